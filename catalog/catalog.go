@@ -0,0 +1,302 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// Options configures a catalog Service
+type Options struct {
+	TTL             time.Duration // How long fetched versions are fresh (default 15m)
+	RefreshInterval time.Duration // Period of the background refresh (default TTL)
+	Source          VersionSource // Where versions come from (default the tags API of each repository host)
+	ManifestPath    string        // Manifest file or directory merged over the built-in one (optional)
+	ReloadInterval  time.Duration // How often ManifestPath is checked for changes (default 5s)
+}
+
+// Service serves the libraries of a manifest with versions from an in-memory cache.
+// Versions are fetched from the source for all libraries in parallel; once the cache is older
+// than the TTL the last known good versions are served while a refresh runs in the background.
+type Service struct {
+	opts Options
+
+	mu            sync.RWMutex
+	ctx           context.Context // given to Start, ends background refreshes
+	manifest      *Manifest
+	manifestStamp string // manifestStamp of ManifestPath when it was loaded
+
+	versions   map[string]ReleaseVersions // last known good versions by library name
+	fetchedAt  time.Time
+	refreshing chan struct{} // closed when the running refresh completes, nil when idle
+	lastErr    error
+}
+
+// New creates a catalog Service and loads its manifest; call Start to refresh it in the background
+func New(opts Options) (*Service, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = 15 * time.Minute
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = opts.TTL
+	}
+	if opts.Source == nil {
+		opts.Source = NewVCSSource(nil)
+	}
+	if opts.ReloadInterval <= 0 {
+		opts.ReloadInterval = 5 * time.Second
+	}
+
+	s := &Service{
+		opts:     opts,
+		ctx:      context.Background(),
+		manifest: DefaultManifest(),
+		versions: make(map[string]ReleaseVersions),
+	}
+	if opts.ManifestPath != "" {
+		if _, err := s.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Start refreshes the catalog now and then every RefreshInterval until ctx is done.
+// With a ManifestPath, the manifest is also reloaded whenever its files change.
+// Refreshes started by Versions stop with ctx as well.
+func (s *Service) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	if s.opts.ManifestPath != "" {
+		go s.watchManifest(ctx)
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.opts.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.Refresh(ctx); err != nil {
+				logger.Warn("Catalog refresh incomplete", logger.Err(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh fetches the versions of all libraries in parallel.
+// Libraries that fail keep their last known good versions. Concurrent calls share one refresh.
+func (s *Service) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	if done := s.refreshing; done != nil {
+		s.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.lastErr
+	}
+	done := make(chan struct{})
+	s.refreshing = done
+	s.mu.Unlock()
+
+	// Waiting callers share the result, so it must not be cut short by the caller's cancellation,
+	// only by the end of the service
+	s.mu.RLock()
+	serviceCtx := s.ctx
+	s.mu.RUnlock()
+	fetched, err := s.fetchAll(serviceCtx)
+
+	s.mu.Lock()
+	for name, versions := range fetched {
+		s.versions[name] = versions
+	}
+	s.fetchedAt = time.Now()
+	s.lastErr = err
+	s.refreshing = nil
+	s.mu.Unlock()
+	close(done)
+
+	logger.Info("Fetched library versions", logger.Int("count", len(fetched)))
+	return err
+}
+
+// Reload loads the manifest again if its files changed since the last load and reports whether
+// it did. An invalid manifest is rejected and the previous one stays in use.
+func (s *Service) Reload() (bool, error) {
+	stamp, err := manifestStamp(s.opts.ManifestPath)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	unchanged := stamp == s.manifestStamp
+	s.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	manifest, err := LoadManifest(s.opts.ManifestPath)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.manifest = manifest
+	s.manifestStamp = stamp
+	s.mu.Unlock()
+
+	logger.Info("Library manifest loaded",
+		logger.String("path", s.opts.ManifestPath),
+		logger.Int("libraries", len(manifest.Libraries)),
+	)
+	return true, nil
+}
+
+// Manifest returns the manifest in use; callers must not modify it
+func (s *Service) Manifest() *Manifest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.manifest
+}
+
+// watchManifest reloads the manifest every ReloadInterval when it changed and fetches the
+// versions of its libraries, until ctx is done
+func (s *Service) watchManifest(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := s.Reload()
+		if err != nil {
+			logger.Error("Failed to reload library manifest", logger.String("path", s.opts.ManifestPath), logger.Err(err))
+			continue
+		}
+		if reloaded {
+			if err := s.Refresh(ctx); err != nil {
+				logger.Warn("Catalog refresh incomplete", logger.Err(err))
+			}
+		}
+	}
+}
+
+// Versions returns the latest versions by library name.
+// The first call waits for the catalog to load; afterwards stale versions are returned
+// immediately and revalidated in the background.
+func (s *Service) Versions(ctx context.Context) map[string]ReleaseVersions {
+	s.mu.RLock()
+	fetchedAt, refreshing, serviceCtx := s.fetchedAt, s.refreshing != nil, s.ctx
+	s.mu.RUnlock()
+
+	switch {
+	case fetchedAt.IsZero():
+		if err := s.Refresh(ctx); err != nil {
+			logger.Warn("Catalog refresh incomplete", logger.Err(err))
+		}
+	case time.Since(fetchedAt) > s.opts.TTL && !refreshing:
+		go func() {
+			if err := s.Refresh(serviceCtx); err != nil {
+				logger.Warn("Catalog refresh incomplete", logger.Err(err))
+			}
+		}()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := make(map[string]ReleaseVersions, len(s.versions))
+	for name, v := range s.versions {
+		versions[name] = v
+	}
+	return versions
+}
+
+// Libraries returns the libraries of the manifest with their latest versions.
+// Pre-releases are only used as Version when includePrerelease is set; libraries without
+// known versions keep their fallback version.
+func (s *Service) Libraries(ctx context.Context, includePrerelease bool) []types.Library {
+	versions := s.Versions(ctx)
+
+	libraries := append([]types.Library(nil), s.Manifest().Libraries...)
+	for i := range libraries {
+		latest := versions[libraries[i].Name]
+		libraries[i].LatestStable = latest.Stable
+		libraries[i].LatestPrerelease = latest.Prerelease
+
+		switch {
+		case includePrerelease && latest.Prerelease != "":
+			libraries[i].Version = latest.Prerelease
+		case latest.Stable != "":
+			libraries[i].Version = latest.Stable
+		}
+	}
+	return libraries
+}
+
+// fetchAll fetches the versions of every library of the manifest concurrently
+func (s *Service) fetchAll(ctx context.Context) (map[string]ReleaseVersions, error) {
+	libraries := s.Manifest().Libraries
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	fetched := make(map[string]ReleaseVersions)
+
+	for _, lib := range libraries {
+		wg.Add(1)
+		go func(lib types.Library) {
+			defer wg.Done()
+
+			versions, err := s.fetchVersions(ctx, lib)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.Debug("Failed to fetch version", logger.String("library", lib.Name), logger.Err(err))
+				errs = append(errs, fmt.Errorf("%s: %w", lib.Name, err))
+				return
+			}
+			fetched[lib.Name] = versions
+		}(lib)
+	}
+
+	wg.Wait()
+	return fetched, errors.Join(errs...)
+}
+
+// fetchVersions lists the versions of a library and picks the latest ones
+func (s *Service) fetchVersions(ctx context.Context, lib types.Library) (ReleaseVersions, error) {
+	versions, err := s.opts.Source.Versions(ctx, lib)
+	if err != nil {
+		return ReleaseVersions{}, err
+	}
+
+	latest := LatestVersions(versions)
+	if latest.Stable == "" && latest.Prerelease == "" {
+		return latest, errors.New("no semver versions found")
+	}
+	return latest, nil
+}
@@ -0,0 +1,155 @@
+package catalog
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// newService creates a Service, failing the test on error
+func newService(t *testing.T, opts Options) *Service {
+	t.Helper()
+	s, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s
+}
+
+func TestServiceLibraries(t *testing.T) {
+	fake := &fakeGitHub{tags: []string{"v1.2.0", "v1.3.0-rc.1", "v1.1.0"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := newService(t, Options{Source: NewGitHubSource(server.URL, ""), TTL: time.Hour})
+
+	libraries := s.Libraries(context.Background(), false)
+	if len(libraries) != len(DefaultManifest().Libraries) {
+		t.Fatalf("got %d libraries, want %d", len(libraries), len(DefaultManifest().Libraries))
+	}
+	for _, lib := range libraries {
+		if lib.Version != "v1.2.0" || lib.LatestStable != "v1.2.0" || lib.LatestPrerelease != "v1.3.0-rc.1" {
+			t.Errorf("%s: version %s, stable %s, pre-release %s", lib.Name, lib.Version, lib.LatestStable, lib.LatestPrerelease)
+		}
+	}
+
+	if lib := s.Libraries(context.Background(), true)[0]; lib.Version != "v1.3.0-rc.1" {
+		t.Errorf("%s: version with pre-releases = %s, want v1.3.0-rc.1", lib.Name, lib.Version)
+	}
+
+	// Fresh cache: no further upstream requests
+	requests := fake.requests.Load()
+	s.Libraries(context.Background(), false)
+	if fake.requests.Load() != requests {
+		t.Errorf("fresh cache made %d upstream requests", fake.requests.Load()-requests)
+	}
+}
+
+func TestServiceStaleWhileRevalidate(t *testing.T) {
+	fake := &fakeGitHub{tags: []string{"v1.2.0"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := newService(t, Options{Source: NewGitHubSource(server.URL, ""), TTL: time.Hour})
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	// Upstream fails after the cache expired: the last known good versions are served
+	fake.fail.Store(true)
+	s.mu.Lock()
+	s.fetchedAt = time.Now().Add(-2 * time.Hour)
+	s.mu.Unlock()
+
+	if v := s.Versions(context.Background())["go-auth"].Stable; v != "v1.2.0" {
+		t.Errorf("stale version = %q, want v1.2.0", v)
+	}
+
+	if err := s.Refresh(context.Background()); err == nil {
+		t.Error("Refresh succeeded against a failing upstream")
+	}
+	if v := s.Versions(context.Background())["go-auth"].Stable; v != "v1.2.0" {
+		t.Errorf("version after failed refresh = %q, want v1.2.0", v)
+	}
+}
+
+func TestServiceFallbackVersions(t *testing.T) {
+	fake := &fakeGitHub{}
+	fake.fail.Store(true)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := newService(t, Options{Source: NewGitHubSource(server.URL, "")})
+	for _, lib := range s.Libraries(context.Background(), false) {
+		if want := DefaultManifest().Version(lib.Name); lib.Version != want || lib.LatestStable != "" {
+			t.Errorf("%s: version %s, stable %s, want fallback %s", lib.Name, lib.Version, lib.LatestStable, want)
+		}
+	}
+}
+
+func TestServiceConcurrentRefresh(t *testing.T) {
+	fake := &fakeGitHub{tags: []string{"v1.0.0"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := newService(t, Options{Source: NewGitHubSource(server.URL, ""), TTL: time.Hour})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Libraries(context.Background(), false)
+		}()
+	}
+	wg.Wait()
+
+	if got, want := fake.requests.Load(), int64(len(DefaultManifest().Libraries)); got != want {
+		t.Errorf("concurrent first loads made %d upstream requests, want %d", got, want)
+	}
+}
+
+// sourceFunc is a VersionSource calling a function
+type sourceFunc func(ctx context.Context, lib types.Library) ([]string, error)
+
+func (f sourceFunc) Versions(ctx context.Context, lib types.Library) ([]string, error) {
+	return f(ctx, lib)
+}
+
+func TestServiceStopsWithStart(t *testing.T) {
+	var fetches, canceled atomic.Int64
+	s := newService(t, Options{TTL: time.Hour, Source: sourceFunc(func(ctx context.Context, _ types.Library) ([]string, error) {
+		fetches.Add(1)
+		if ctx.Err() != nil {
+			canceled.Add(1)
+		}
+		return []string{"v1.0.0"}, ctx.Err()
+	})})
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Start(ctx)
+
+	// Start refreshes once with the ended context, then stale versions are revalidated in the
+	// background with it as well
+	libraries := int64(len(DefaultManifest().Libraries))
+	deadline := time.Now().Add(5 * time.Second)
+	for canceled.Load() < 2*libraries && time.Now().Before(deadline) {
+		s.mu.Lock()
+		s.fetchedAt = time.Now().Add(-2 * time.Hour)
+		s.mu.Unlock()
+		s.Versions(context.Background())
+		time.Sleep(5 * time.Millisecond)
+	}
+	if canceled.Load() < 2*libraries {
+		t.Errorf("%d of %d fetches were canceled, want the ones of Start and of a background refresh", canceled.Load(), fetches.Load())
+	}
+}
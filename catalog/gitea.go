@@ -0,0 +1,46 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// giteaTagsPerPage is the default maximum page size of the Gitea API
+const giteaTagsPerPage = 50
+
+// GiteaSource lists library versions from the tags of their Gitea (or Forgejo) repository
+type GiteaSource struct {
+	baseURL string
+	api     *tagClient
+}
+
+// NewGiteaSource creates a Gitea source for the API at baseURL (e.g. https://codeberg.org/api/v1).
+// A non-empty token authenticates requests to private repositories.
+func NewGiteaSource(baseURL, token string) *GiteaSource {
+	header := make(http.Header)
+	if token != "" {
+		header.Set("Authorization", "token "+token)
+	}
+
+	return &GiteaSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		api:     newTagClient("Gitea", header),
+	}
+}
+
+// Versions implements VersionSource for libraries whose RepoURL is a Gitea repository
+func (g *GiteaSource) Versions(ctx context.Context, lib types.Library) ([]string, error) {
+	path, err := repoPath(lib)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, _ := strings.Cut(path, "/")
+	if strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("repository URL %q is not of the form https://host/owner/repo", lib.RepoURL)
+	}
+	return g.api.tags(ctx, fmt.Sprintf("%s/repos/%s/%s/tags?limit=%d", g.baseURL, owner, repo, giteaTagsPerPage))
+}
@@ -0,0 +1,62 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// tagsPerPage is the largest page size of the GitHub and GitLab tags APIs
+const tagsPerPage = 100
+
+// GitHubTag represents GitHub API tag response
+type GitHubTag struct {
+	Name string `json:"name"`
+}
+
+// GitHubSource lists library versions from the tags of their GitHub repository
+type GitHubSource struct {
+	baseURL string
+	api     *tagClient
+}
+
+// NewGitHubSource creates a GitHub source for the API at baseURL (default https://api.github.com).
+// A non-empty token authenticates requests for a higher rate limit.
+func NewGitHubSource(baseURL, token string) *GitHubSource {
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+
+	header := make(http.Header)
+	header.Set("Accept", "application/vnd.github.v3+json")
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	return &GitHubSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		api:     newTagClient("GitHub", header),
+	}
+}
+
+// Versions implements VersionSource for libraries whose RepoURL is a GitHub repository
+func (g *GitHubSource) Versions(ctx context.Context, lib types.Library) ([]string, error) {
+	path, err := repoPath(lib)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, _ := strings.Cut(path, "/")
+	if strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("repository URL %q is not of the form https://github.com/owner/repo", lib.RepoURL)
+	}
+	return g.tags(ctx, owner, repo)
+}
+
+// tags fetches every tag name of a repository, following the pagination links
+func (g *GitHubSource) tags(ctx context.Context, owner, repo string) ([]string, error) {
+	// Use tags API instead of releases API
+	return g.api.tags(ctx, fmt.Sprintf("%s/repos/%s/%s/tags?per_page=%d", g.baseURL, owner, repo, tagsPerPage))
+}
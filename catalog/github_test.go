@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// fakeGitHub serves the tags API for every repository of an owner, in pages of two tags.
+// Responses carry an ETag and honor If-None-Match.
+type fakeGitHub struct {
+	tags        []string
+	token       string // required bearer token, if set
+	requests    atomic.Int64
+	notModified atomic.Int64
+	fail        atomic.Bool
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests.Add(1)
+	if f.fail.Load() {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if f.token != "" && r.Header.Get("Authorization") != "Bearer "+f.token {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Query().Get("per_page") != "100" {
+		http.NotFound(w, r)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	etag := fmt.Sprintf(`"page-%d"`, page)
+	if r.Header.Get("If-None-Match") == etag {
+		f.notModified.Add(1)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	end := min(2*page+2, len(f.tags))
+	if end < len(f.tags) {
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?per_page=100&page=%d>; rel="next"`, r.Host, r.URL.Path, page+1))
+	}
+	w.Header().Set("ETag", etag)
+
+	var tags []GitHubTag
+	for _, name := range f.tags[2*page : end] {
+		tags = append(tags, GitHubTag{Name: name})
+	}
+	json.NewEncoder(w).Encode(tags)
+}
+
+func TestGitHubClientTags(t *testing.T) {
+	fake := &fakeGitHub{tags: []string{"v1.9.0", "v1.8.0", "v1.10.0-rc.1", "v1.10.0", "v1.0.0"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewGitHubSource(server.URL, "")
+
+	tags, err := client.tags(context.Background(), "OkanUysal", "go-logger")
+	if err != nil {
+		t.Fatalf("tags: %v", err)
+	}
+	if len(tags) != 5 || fake.requests.Load() != 3 {
+		t.Fatalf("fetched %d tags in %d requests, want 5 in 3: %v", len(tags), fake.requests.Load(), tags)
+	}
+	if got, want := LatestVersions(tags), (ReleaseVersions{Stable: "v1.10.0"}); got != want {
+		t.Errorf("LatestVersions = %+v, want %+v", got, want)
+	}
+
+	// Cached pages are revalidated
+	tags, err = client.tags(context.Background(), "OkanUysal", "go-logger")
+	if err != nil {
+		t.Fatalf("tags: %v", err)
+	}
+	if len(tags) != 5 || fake.notModified.Load() != 3 {
+		t.Errorf("refetched %d tags with %d not-modified responses, want 5 with 3", len(tags), fake.notModified.Load())
+	}
+}
+
+func TestGitHubSourceVersions(t *testing.T) {
+	fake := &fakeGitHub{tags: []string{"v1.0.0"}, token: "secret"}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	lib := types.Library{Name: "go-auth", RepoURL: "https://github.com/OkanUysal/go-auth"}
+
+	if _, err := NewGitHubSource(server.URL, "").Versions(context.Background(), lib); err == nil {
+		t.Error("unauthenticated request succeeded")
+	}
+
+	versions, err := NewGitHubSource(server.URL, "secret").Versions(context.Background(), lib)
+	if err != nil || len(versions) != 1 || versions[0] != "v1.0.0" {
+		t.Errorf("Versions = %v, %v; want [v1.0.0]", versions, err)
+	}
+
+	lib.RepoURL = "https://github.com/OkanUysal"
+	if _, err := NewGitHubSource(server.URL, "").Versions(context.Background(), lib); err == nil {
+		t.Error("expected an error for a repository URL without a repository name")
+	}
+}
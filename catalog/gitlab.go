@@ -0,0 +1,46 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// GitLabSource lists library versions from the tags of their GitLab project.
+// Projects may live in nested groups (e.g. https://gitlab.com/acme/platform/go-queue).
+type GitLabSource struct {
+	baseURL string
+	api     *tagClient
+}
+
+// NewGitLabSource creates a GitLab source for the API at baseURL (default https://gitlab.com/api/v4).
+// A non-empty token is sent as a personal, project or group access token.
+func NewGitLabSource(baseURL, token string) *GitLabSource {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com/api/v4"
+	}
+
+	header := make(http.Header)
+	if token != "" {
+		header.Set("PRIVATE-TOKEN", token)
+	}
+
+	return &GitLabSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		api:     newTagClient("GitLab", header),
+	}
+}
+
+// Versions implements VersionSource for libraries whose RepoURL is a GitLab project
+func (g *GitLabSource) Versions(ctx context.Context, lib types.Library) ([]string, error) {
+	path, err := repoPath(lib)
+	if err != nil {
+		return nil, err
+	}
+	// The project is addressed by its URL-encoded full path
+	return g.api.tags(ctx, fmt.Sprintf("%s/projects/%s/repository/tags?per_page=%d", g.baseURL, url.PathEscape(path), tagsPerPage))
+}
@@ -0,0 +1,259 @@
+package catalog
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/goccy/go-yaml"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// defaultManifestData is the built-in catalog
+//
+//go:embed libraries.yaml
+var defaultManifestData []byte
+
+// BuiltinSource is the source of the libraries of the built-in manifest.
+// Their names are not prefixed, so requests keep using names like "go-logger".
+const BuiltinSource = "builtin"
+
+// sourcePattern matches source names; they prefix library names and must stay URL-friendly
+var sourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Manifest lists the libraries offered by the catalog.
+// Entries use the JSON field names of types.Library; Version is the fallback version.
+// Source names the catalog of every entry that does not set its own (default "builtin").
+type Manifest struct {
+	Source    string          `json:"source,omitempty"`
+	Libraries []types.Library `json:"libraries"`
+}
+
+var defaultManifest = sync.OnceValue(func() *Manifest {
+	m, err := ParseManifest(defaultManifestData)
+	if err == nil {
+		err = m.checkRules()
+	}
+	if err != nil {
+		panic("catalog: invalid built-in manifest: " + err.Error())
+	}
+	return m
+})
+
+// DefaultManifest returns the built-in manifest; callers must not modify it
+func DefaultManifest() *Manifest {
+	return defaultManifest()
+}
+
+// ParseManifest parses a YAML or JSON manifest, validates its entries and fills in their defaults:
+// the source, the module path derived from the repository URL and the provider of well-known hosts.
+// Libraries of a source other than "builtin" are named "source/name".
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.UnmarshalWithOptions(data, &m, yaml.Strict()); err != nil {
+		return nil, err
+	}
+	if m.Source == "" {
+		m.Source = BuiltinSource
+	}
+	if !sourcePattern.MatchString(m.Source) {
+		return nil, fmt.Errorf("source %q must be lowercase letters, digits and dashes", m.Source)
+	}
+
+	seen := make(map[string]bool)
+	var errs []error
+	for i := range m.Libraries {
+		lib := &m.Libraries[i]
+		if err := normalizeLibrary(lib, m.Source); err != nil {
+			errs = append(errs, fmt.Errorf("library %s: %w", cmp.Or(lib.Name, strconv.Itoa(i+1)), err))
+			continue
+		}
+		if seen[lib.Name] {
+			errs = append(errs, fmt.Errorf("library %s: listed more than once", lib.Name))
+		}
+		seen[lib.Name] = true
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &m, nil
+}
+
+// normalizeLibrary validates a manifest entry and fills in its defaults
+func normalizeLibrary(lib *types.Library, source string) error {
+	switch {
+	case lib.Name == "":
+		return errors.New("name is required")
+	case strings.Contains(lib.Name, "/"):
+		return errors.New("name must not contain a slash, use source to namespace it")
+	case lib.RepoURL == "":
+		return errors.New("repoURL is required")
+	case lib.Version != "" && !semver.IsValid(lib.Version):
+		return fmt.Errorf("version %q is not a semantic version", lib.Version)
+	}
+
+	for _, database := range lib.Databases {
+		if !slices.Contains(Databases, database) {
+			return fmt.Errorf("database %q is not one of %s", database, strings.Join(Databases, ", "))
+		}
+	}
+	if len(lib.Databases) > 0 {
+		lib.RequiresDB = true
+	}
+
+	lib.Source = cmp.Or(lib.Source, source)
+	if !sourcePattern.MatchString(lib.Source) {
+		return fmt.Errorf("source %q must be lowercase letters, digits and dashes", lib.Source)
+	}
+	if lib.Source != BuiltinSource {
+		lib.Name = lib.Source + "/" + lib.Name
+	}
+
+	repo, err := url.Parse(lib.RepoURL)
+	if err != nil || repo.Host == "" || (repo.Scheme != "https" && repo.Scheme != "http") {
+		return fmt.Errorf("repoURL %q is not an http(s) URL", lib.RepoURL)
+	}
+	if lib.ModulePath == "" {
+		lib.ModulePath = repo.Host + strings.TrimSuffix(strings.TrimSuffix(repo.Path, "/"), ".git")
+	}
+	if err := module.CheckPath(lib.ModulePath); err != nil {
+		return err
+	}
+
+	lib.Provider = cmp.Or(lib.Provider, hostProviders[repo.Host])
+	switch lib.Provider {
+	case "", ProviderGitHub, ProviderGitLab, ProviderGitea:
+	default:
+		return fmt.Errorf("provider %q is not one of %s, %s, %s", lib.Provider, ProviderGitHub, ProviderGitLab, ProviderGitea)
+	}
+	return nil
+}
+
+// LoadManifest loads the built-in manifest with the manifest file at path merged over it.
+// If path is a directory, its .yaml, .yml and .json files are merged in name order.
+// Libraries replace the ones with the same name and new libraries are appended.
+func LoadManifest(path string) (*Manifest, error) {
+	files, err := manifestFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &Manifest{Source: BuiltinSource, Libraries: DefaultManifest().Libraries}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		m, err := ParseManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		merged = merged.merge(m)
+	}
+	if err := merged.checkRules(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Library returns the library with the given name
+func (m *Manifest) Library(name string) (types.Library, bool) {
+	for _, lib := range m.Libraries {
+		if lib.Name == name {
+			return lib, true
+		}
+	}
+	return types.Library{}, false
+}
+
+// Version returns the fallback version of a library, "" if it is not listed
+func (m *Manifest) Version(name string) string {
+	lib, _ := m.Library(name)
+	return lib.Version
+}
+
+// ModulePath returns the module path of a library, "" if it is not listed
+func (m *Manifest) ModulePath(name string) string {
+	lib, _ := m.Library(name)
+	return lib.ModulePath
+}
+
+// merge returns a manifest with the libraries of other replacing or extending the ones of m
+func (m *Manifest) merge(other *Manifest) *Manifest {
+	merged := &Manifest{Source: m.Source, Libraries: make([]types.Library, 0, len(m.Libraries)+len(other.Libraries))}
+	index := make(map[string]int)
+	for _, lib := range m.Libraries {
+		index[lib.Name] = len(merged.Libraries)
+		merged.Libraries = append(merged.Libraries, lib)
+	}
+	for _, lib := range other.Libraries {
+		if i, ok := index[lib.Name]; ok {
+			merged.Libraries[i] = lib
+			continue
+		}
+		index[lib.Name] = len(merged.Libraries)
+		merged.Libraries = append(merged.Libraries, lib)
+	}
+	return merged
+}
+
+// manifestFiles returns path itself, or the manifest files of a directory sorted by name
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// manifestStamp summarizes the names, sizes and modification times of the manifest files at path,
+// so a change can be detected without reading them
+func manifestStamp(path string) (string, error) {
+	files, err := manifestFiles(path)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,185 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultManifest(t *testing.T) {
+	m := DefaultManifest()
+	if len(m.Libraries) != 10 {
+		t.Fatalf("built-in manifest has %d libraries, want 10", len(m.Libraries))
+	}
+
+	lib, ok := m.Library("go-logger")
+	if !ok {
+		t.Fatal("go-logger missing from the built-in manifest")
+	}
+	if lib.Version != "v1.0.1" || lib.RepoURL != "https://github.com/OkanUysal/go-logger" || lib.Category != "Observability" {
+		t.Errorf("go-logger = %+v", lib)
+	}
+	if lib, _ := m.Library("go-migration"); !lib.RequiresDB {
+		t.Error("go-migration does not require a database")
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(`{"libraries": [{"name": "go-queue", "repoURL": "https://github.com/acme/go-queue", "version": "v0.3.0"}]}`))
+	if err != nil {
+		t.Fatalf("ParseManifest JSON: %v", err)
+	}
+	if len(m.Libraries) != 1 || m.Version("go-queue") != "v0.3.0" {
+		t.Errorf("JSON manifest = %+v", m.Libraries)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"missing name", "libraries:\n  - repoURL: https://github.com/acme/x\n", "library 1: name is required"},
+		{"duplicate", "libraries:\n  - {name: x, repoURL: https://github.com/acme/x}\n  - {name: x, repoURL: https://github.com/acme/x}\n", "library x: listed more than once"},
+		{"missing repo", "libraries:\n  - name: x\n", "library x: repoURL is required"},
+		{"bad version", "libraries:\n  - {name: x, repoURL: https://github.com/acme/x, version: latest}\n", `version "latest" is not a semantic version`},
+		{"unknown field", "libraries:\n  - {name: x, repoURL: https://github.com/acme/x, homepage: https://acme.dev}\n", "homepage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseManifestSources(t *testing.T) {
+	m, err := ParseManifest([]byte(`source: acme
+libraries:
+  - name: go-queue
+    repoURL: https://gitlab.com/acme/platform/go-queue.git
+  - name: go-cron
+    repoURL: https://git.acme.dev/acme/go-cron
+    modulePath: go.acme.dev/cron
+    provider: gitea
+  - name: go-logger
+    source: partner
+    repoURL: https://github.com/partner/go-logger
+`))
+	if err != nil {
+		t.Fatalf("ParseManifest: %v", err)
+	}
+
+	type entry struct{ Name, Source, ModulePath, Provider string }
+	want := []entry{
+		{"acme/go-queue", "acme", "gitlab.com/acme/platform/go-queue", ProviderGitLab},
+		{"acme/go-cron", "acme", "go.acme.dev/cron", ProviderGitea},
+		{"partner/go-logger", "partner", "github.com/partner/go-logger", ProviderGitHub},
+	}
+	for i, lib := range m.Libraries {
+		if got := (entry{lib.Name, lib.Source, lib.ModulePath, lib.Provider}); got != want[i] {
+			t.Errorf("library %d = %+v, want %+v", i+1, got, want[i])
+		}
+	}
+
+	if lib, _ := DefaultManifest().Library("go-auth"); lib.Source != BuiltinSource || lib.ModulePath != "github.com/OkanUysal/go-auth" {
+		t.Errorf("built-in go-auth = %+v", lib)
+	}
+
+	for manifest, want := range map[string]string{
+		"source: Acme\nlibraries: []\n": `source "Acme"`,
+		"libraries:\n  - {name: x, repoURL: https://github.com/acme/x, modulePath: acme}\n":    "malformed module path",
+		"libraries:\n  - {name: x, repoURL: https://github.com/acme/x, provider: bitbucket}\n": `provider "bitbucket"`,
+		"libraries:\n  - {name: acme/x, repoURL: https://github.com/acme/x}\n":                 "must not contain a slash",
+		"libraries:\n  - {name: x, repoURL: github.com/acme/x}\n":                              "not an http(s) URL",
+	} {
+		if _, err := ParseManifest([]byte(manifest)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error = %v, want %q", manifest, err, want)
+		}
+	}
+}
+
+func TestLoadManifestDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "10-logger.yaml"), "libraries:\n  - {name: go-logger, repoURL: https://github.com/acme/go-logger, version: v2.0.0}\n")
+	writeFile(t, filepath.Join(dir, "20-queue.json"), `{"libraries": [{"name": "go-queue", "repoURL": "https://github.com/acme/go-queue"}]}`)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a manifest")
+
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if len(m.Libraries) != 11 {
+		t.Fatalf("merged manifest has %d libraries, want 11", len(m.Libraries))
+	}
+	if lib, _ := m.Library("go-logger"); lib.Version != "v2.0.0" || lib.RepoURL != "https://github.com/acme/go-logger" {
+		t.Errorf("go-logger not replaced: %+v", lib)
+	}
+	if last := m.Libraries[len(m.Libraries)-1]; last.Name != "go-queue" {
+		t.Errorf("last library = %s, want go-queue appended", last.Name)
+	}
+	if DefaultManifest().Version("go-logger") != "v1.0.1" {
+		t.Error("merge modified the built-in manifest")
+	}
+}
+
+func TestServiceReloadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "libraries.yaml")
+	writeFile(t, path, "libraries:\n  - {name: go-queue, repoURL: https://github.com/acme/go-queue, version: v0.1.0}\n")
+
+	s := newService(t, Options{ManifestPath: path})
+	if s.Manifest().Version("go-queue") != "v0.1.0" {
+		t.Fatal("manifest file not loaded")
+	}
+
+	if reloaded, err := s.Reload(); reloaded || err != nil {
+		t.Errorf("Reload of an unchanged manifest = %v, %v", reloaded, err)
+	}
+
+	// Invalid edits are rejected and the previous manifest stays in use
+	writeFile(t, path, "libraries:\n  - {name: go-queue}\n")
+	touch(t, path, time.Now().Add(time.Second))
+	if _, err := s.Reload(); err == nil {
+		t.Error("Reload accepted an invalid manifest")
+	}
+	if s.Manifest().Version("go-queue") != "v0.1.0" {
+		t.Error("invalid manifest replaced the previous one")
+	}
+
+	writeFile(t, path, "libraries:\n  - {name: go-queue, repoURL: https://github.com/acme/go-queue, version: v0.2.0}\n")
+	touch(t, path, time.Now().Add(2*time.Second))
+	if reloaded, err := s.Reload(); !reloaded || err != nil {
+		t.Fatalf("Reload = %v, %v", reloaded, err)
+	}
+	if s.Manifest().Version("go-queue") != "v0.2.0" {
+		t.Error("changed manifest not reloaded")
+	}
+}
+
+func TestNewInvalidManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "libraries.yaml")
+	writeFile(t, path, "libraries: [")
+
+	if _, err := New(Options{ManifestPath: path}); err == nil {
+		t.Error("New accepted an invalid manifest")
+	}
+}
+
+// writeFile writes a test file
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch sets the modification time of a file, so a rewrite is detected on filesystems with coarse timestamps
+func touch(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,120 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// Databases are the database types a generated project can use, besides "none"
+var Databases = []string{"postgres", "mysql", "mongodb"}
+
+// Rules of the catalog, as reported in violations
+const (
+	RuleRequires  = "requires"
+	RuleConflicts = "conflicts"
+	RuleDatabase  = "database"
+)
+
+// Resolve checks a library selection against the requires, conflicts and database rules of the manifest.
+// With addImplied, the libraries implied by the selection are appended first, transitively.
+// Libraries that are not in the manifest are kept as they are.
+func (m *Manifest) Resolve(libraries []string, database string, addImplied bool) ([]string, []types.Violation) {
+	selected := slices.Clone(libraries)
+	if addImplied {
+		// selected grows while it is walked, so implied libraries get their implications added too
+		for i := 0; i < len(selected); i++ {
+			lib, _ := m.Library(selected[i])
+			for _, implied := range lib.Implies {
+				if !slices.Contains(selected, implied) {
+					selected = append(selected, implied)
+				}
+			}
+		}
+	}
+
+	if database == "" {
+		database = "none"
+	}
+
+	var violations []types.Violation
+	reported := make(map[[2]string]bool) // conflicting pairs, declared on either side
+	for _, name := range selected {
+		lib, ok := m.Library(name)
+		if !ok {
+			continue
+		}
+
+		for _, required := range lib.Requires {
+			if !slices.Contains(selected, required) {
+				violations = append(violations, types.Violation{
+					Rule:    RuleRequires,
+					Library: name,
+					Target:  required,
+					Message: fmt.Sprintf("%s requires %s", name, required),
+				})
+			}
+		}
+
+		for _, conflict := range lib.Conflicts {
+			pair := [2]string{min(name, conflict), max(name, conflict)}
+			if slices.Contains(selected, conflict) && !reported[pair] {
+				reported[pair] = true
+				violations = append(violations, types.Violation{
+					Rule:    RuleConflicts,
+					Library: name,
+					Target:  conflict,
+					Message: fmt.Sprintf("%s cannot be used with %s", name, conflict),
+				})
+			}
+		}
+
+		switch {
+		case lib.RequiresDB && database == "none":
+			violations = append(violations, types.Violation{
+				Rule:    RuleDatabase,
+				Library: name,
+				Target:  database,
+				Message: fmt.Sprintf("%s requires a database", name),
+			})
+		case len(lib.Databases) > 0 && database != "none" && !slices.Contains(lib.Databases, database):
+			violations = append(violations, types.Violation{
+				Rule:    RuleDatabase,
+				Library: name,
+				Target:  database,
+				Message: fmt.Sprintf("%s does not support %s, use %s", name, database, strings.Join(lib.Databases, " or ")),
+			})
+		}
+	}
+
+	return selected, violations
+}
+
+// checkRules verifies that the rules of every library refer to libraries of the manifest
+func (m *Manifest) checkRules() error {
+	var errs []error
+	for _, lib := range m.Libraries {
+		rules := []struct {
+			name      string
+			libraries []string
+		}{
+			{"requires", lib.Requires},
+			{"conflicts", lib.Conflicts},
+			{"implies", lib.Implies},
+		}
+		for _, rule := range rules {
+			for _, name := range rule.libraries {
+				switch _, ok := m.Library(name); {
+				case name == lib.Name:
+					errs = append(errs, fmt.Errorf("library %s: %s itself", lib.Name, rule.name))
+				case !ok:
+					errs = append(errs, fmt.Errorf("library %s: %s unknown library %q", lib.Name, rule.name, name))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
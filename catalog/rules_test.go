@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// rulesManifest is a manifest exercising every rule
+const rulesManifest = `libraries:
+  - {name: a, repoURL: https://github.com/acme/a, implies: [b]}
+  - {name: b, repoURL: https://github.com/acme/b, implies: [c], conflicts: [d]}
+  - {name: c, repoURL: https://github.com/acme/c}
+  - {name: d, repoURL: https://github.com/acme/d, conflicts: [b]}
+  - {name: e, repoURL: https://github.com/acme/e, requires: [c]}
+  - {name: sql, repoURL: https://github.com/acme/sql, databases: [postgres, mysql]}
+  - {name: db, repoURL: https://github.com/acme/db, requiresDB: true}
+`
+
+func TestManifestResolve(t *testing.T) {
+	m, err := ParseManifest([]byte(rulesManifest))
+	if err != nil {
+		t.Fatalf("ParseManifest: %v", err)
+	}
+	if err := m.checkRules(); err != nil {
+		t.Fatalf("checkRules: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		libraries  []string
+		database   string
+		addImplied bool
+		want       []string
+		violations []string // rule:library:target
+	}{
+		{"valid", []string{"a", "b", "c", "unknown"}, "none", false, []string{"a", "b", "c", "unknown"}, nil},
+		{"implied", []string{"a", "e"}, "", true, []string{"a", "e", "b", "c"}, nil},
+		{"not implied", []string{"a", "e"}, "", false, []string{"a", "e"}, []string{"requires:e:c"}},
+		{"conflict reported once", []string{"b", "d"}, "none", false, []string{"b", "d"}, []string{"conflicts:b:d"}},
+		{"implied conflict", []string{"d", "a"}, "none", true, []string{"d", "a", "b", "c"}, []string{"conflicts:d:b"}},
+		{"no database", []string{"sql", "db"}, "", false, []string{"sql", "db"}, []string{"database:sql:none", "database:db:none"}},
+		{"unsupported database", []string{"sql", "db"}, "mongodb", false, []string{"sql", "db"}, []string{"database:sql:mongodb"}},
+		{"supported database", []string{"sql", "db"}, "mysql", false, []string{"sql", "db"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := m.Resolve(tt.libraries, tt.database, tt.addImplied)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("libraries = %v, want %v", got, tt.want)
+			}
+			if gotViolations := violationKeys(violations); !slices.Equal(gotViolations, tt.violations) {
+				t.Errorf("violations = %v, want %v", gotViolations, tt.violations)
+			}
+		})
+	}
+}
+
+func TestDefaultManifestRules(t *testing.T) {
+	m := DefaultManifest()
+
+	_, violations := m.Resolve([]string{"go-migration"}, "none", false)
+	if got := violationKeys(violations); !slices.Equal(got, []string{"database:go-migration:none"}) {
+		t.Errorf("go-migration without database: violations = %v", got)
+	}
+
+	libraries, violations := m.Resolve([]string{"go-pagination", "go-migration"}, "postgres", true)
+	if len(violations) > 0 || !slices.Equal(libraries, []string{"go-pagination", "go-migration", "go-response"}) {
+		t.Errorf("Resolve = %v, %v", libraries, violations)
+	}
+}
+
+func TestLoadManifestUnknownRuleTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "libraries.yaml")
+	writeFile(t, path, "libraries:\n  - {name: go-queue, repoURL: https://github.com/acme/go-queue, requires: [go-redis, go-queue]}\n")
+
+	_, err := LoadManifest(path)
+	if err == nil || !strings.Contains(err.Error(), `requires unknown library "go-redis"`) || !strings.Contains(err.Error(), "requires itself") {
+		t.Errorf("error = %v", err)
+	}
+
+	if _, err := ParseManifest([]byte("libraries:\n  - {name: x, repoURL: https://github.com/acme/x, databases: [sqlite]}\n")); err == nil {
+		t.Error("unknown database accepted")
+	}
+}
+
+// violationKeys summarizes violations as rule:library:target
+func violationKeys(violations []types.Violation) []string {
+	var keys []string
+	for _, v := range violations {
+		keys = append(keys, v.Rule+":"+v.Library+":"+v.Target)
+	}
+	return keys
+}
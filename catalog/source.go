@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/types"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// VersionSource lists the published versions of a library
+type VersionSource interface {
+	Versions(ctx context.Context, lib types.Library) ([]string, error)
+}
+
+// ReleaseVersions holds the latest stable and pre-release versions of a library.
+// Prerelease is only set when it is newer than Stable.
+type ReleaseVersions struct {
+	Stable     string
+	Prerelease string
+}
+
+// LatestVersions returns the highest stable and pre-release versions by semantic version precedence.
+// Tags that are not valid semantic versions (e.g. "latest") are ignored.
+func LatestVersions(tags []string) ReleaseVersions {
+	var latest ReleaseVersions
+	for _, tag := range tags {
+		if !semver.IsValid(tag) {
+			continue
+		}
+		if semver.Prerelease(tag) == "" {
+			if semver.Compare(tag, latest.Stable) > 0 {
+				latest.Stable = tag
+			}
+		} else if semver.Compare(tag, latest.Prerelease) > 0 {
+			latest.Prerelease = tag
+		}
+	}
+
+	if latest.Stable != "" && semver.Compare(latest.Prerelease, latest.Stable) < 0 {
+		latest.Prerelease = ""
+	}
+	return latest
+}
+
+// ProxySource lists library versions through the Go module proxy protocol.
+// It works with any GOPROXY (proxy.golang.org, Athens, Artifactory...) and does not need
+// access to the VCS host.
+type ProxySource struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewProxySource creates a module proxy source for the proxy at baseURL
+func NewProxySource(baseURL string) *ProxySource {
+	return &ProxySource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Versions implements VersionSource using /@v/list, or /@latest for modules without tagged versions
+func (p *ProxySource) Versions(ctx context.Context, lib types.Library) ([]string, error) {
+	escPath, err := module.EscapePath(modulePath(lib))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.get(ctx, escPath+"/@v/list")
+	if err != nil {
+		return nil, err
+	}
+	if versions := strings.Fields(string(body)); len(versions) > 0 {
+		return versions, nil
+	}
+
+	body, err = p.get(ctx, escPath+"/@latest")
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		Version string
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+	return []string{info.Version}, nil
+}
+
+// get fetches a path below the proxy base URL
+func (p *ProxySource) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("module proxy returned %s for %s", resp.Status, path)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// modulePath returns the module path of a library, by default its repository URL without the scheme
+func modulePath(lib types.Library) string {
+	if lib.ModulePath != "" {
+		return lib.ModulePath
+	}
+	if u, err := url.Parse(lib.RepoURL); err == nil && u.Host != "" {
+		return u.Host + strings.TrimSuffix(u.Path, "/")
+	}
+	return lib.RepoURL
+}
@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+func TestLatestVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want ReleaseVersions
+	}{
+		{
+			name: "lexical order is not version order",
+			tags: []string{"v1.9.0", "v1.10.0", "v1.2.0"},
+			want: ReleaseVersions{Stable: "v1.10.0"},
+		},
+		{
+			name: "newer pre-release",
+			tags: []string{"v2.0.0-rc.1", "v1.4.0", "v2.0.0-beta.2", "v1.3.9"},
+			want: ReleaseVersions{Stable: "v1.4.0", Prerelease: "v2.0.0-rc.1"},
+		},
+		{
+			name: "pre-release superseded by its release",
+			tags: []string{"v1.1.0-rc.1", "v1.1.0", "v1.0.0"},
+			want: ReleaseVersions{Stable: "v1.1.0"},
+		},
+		{
+			name: "pre-releases only",
+			tags: []string{"v0.1.0-alpha", "v0.1.0-beta"},
+			want: ReleaseVersions{Prerelease: "v0.1.0-beta"},
+		},
+		{
+			name: "invalid tags ignored",
+			tags: []string{"latest", "1.5.0", "v1.0", "v1.0.1"},
+			want: ReleaseVersions{Stable: "v1.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LatestVersions(tt.tags); got != tt.want {
+				t.Errorf("LatestVersions(%v) = %+v, want %+v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxySourceVersions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/github.com/!okan!uysal/go-auth/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v1.0.0\nv1.2.0\nv1.1.0\n"))
+	})
+	mux.HandleFunc("/github.com/!okan!uysal/go-cache/@v/list", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/github.com/!okan!uysal/go-cache/@latest", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Version":"v0.0.0-20240101000000-abcdefabcdef","Time":"2024-01-01T00:00:00Z"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source := NewProxySource(server.URL)
+
+	tests := []struct {
+		lib  types.Library
+		want ReleaseVersions
+	}{
+		{
+			lib:  types.Library{Name: "go-auth", RepoURL: "https://github.com/OkanUysal/go-auth"},
+			want: ReleaseVersions{Stable: "v1.2.0"},
+		},
+		{
+			lib:  types.Library{Name: "go-cache", RepoURL: "https://github.com/OkanUysal/go-cache"},
+			want: ReleaseVersions{Prerelease: "v0.0.0-20240101000000-abcdefabcdef"},
+		},
+	}
+
+	for _, tt := range tests {
+		versions, err := source.Versions(context.Background(), tt.lib)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.lib.Name, err)
+		}
+		if got := LatestVersions(versions); got != tt.want {
+			t.Errorf("%s: LatestVersions(%v) = %+v, want %+v", tt.lib.Name, versions, got, tt.want)
+		}
+	}
+
+	missing := types.Library{Name: "go-missing", RepoURL: "https://github.com/OkanUysal/go-missing"}
+	if _, err := source.Versions(context.Background(), missing); err == nil {
+		t.Error("expected an error for a module unknown to the proxy")
+	}
+}
@@ -0,0 +1,250 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// Tags APIs a library repository can be hosted behind
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea  = "gitea"
+)
+
+// hostProviders are the providers of well-known hosts; other hosts must set provider in the manifest
+var hostProviders = map[string]string{
+	"github.com":   ProviderGitHub,
+	"gitlab.com":   ProviderGitLab,
+	"codeberg.org": ProviderGitea,
+	"gitea.com":    ProviderGitea,
+}
+
+// maxTagPages bounds the number of tag pages fetched per repository
+const maxTagPages = 10
+
+// VCSSource lists library versions from the tags API of the host each library lives on,
+// picked by the library's Provider. Self-hosted GitLab and Gitea APIs are found below the
+// repository host unless the library sets APIURL.
+type VCSSource struct {
+	tokens map[string]string // API tokens by repository host
+
+	mu      sync.Mutex
+	sources map[string]VersionSource // keyed by provider, API URL and repository host
+}
+
+// NewVCSSource creates a VCS source. Tokens are keyed by repository host (e.g. "github.com")
+// and only sent to the API of that host.
+func NewVCSSource(tokens map[string]string) *VCSSource {
+	return &VCSSource{
+		tokens:  tokens,
+		sources: make(map[string]VersionSource),
+	}
+}
+
+// apiToken returns the token of a repository host when the API is served by that host, either on
+// the host itself or on its api. subdomain (api.github.com for github.com). An API on any other
+// host gets no token, so a manifest cannot send one host's credentials elsewhere.
+func apiToken(tokens map[string]string, repoHost, apiURL string) string {
+	api, err := url.Parse(apiURL)
+	if err != nil || (api.Host != repoHost && api.Host != "api."+repoHost) {
+		return ""
+	}
+	return tokens[repoHost]
+}
+
+// Versions implements VersionSource
+func (v *VCSSource) Versions(ctx context.Context, lib types.Library) ([]string, error) {
+	repo, err := url.Parse(lib.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := lib.APIURL
+	if apiURL == "" {
+		switch lib.Provider {
+		case ProviderGitHub:
+			apiURL = "https://api.github.com"
+		case ProviderGitLab:
+			apiURL = repo.Scheme + "://" + repo.Host + "/api/v4"
+		case ProviderGitea:
+			apiURL = repo.Scheme + "://" + repo.Host + "/api/v1"
+		}
+	}
+
+	// The repository host is part of the key, so a token never reaches an API shared with another host
+	key := lib.Provider + " " + apiURL + " " + repo.Host
+	v.mu.Lock()
+	source, ok := v.sources[key]
+	if !ok {
+		token := apiToken(v.tokens, repo.Host, apiURL)
+		switch lib.Provider {
+		case ProviderGitHub:
+			source = NewGitHubSource(apiURL, token)
+		case ProviderGitLab:
+			source = NewGitLabSource(apiURL, token)
+		case ProviderGitea:
+			source = NewGiteaSource(apiURL, token)
+		default:
+			v.mu.Unlock()
+			return nil, fmt.Errorf("no tags API known for %s, set provider in the manifest", repo.Host)
+		}
+		v.sources[key] = source
+	}
+	v.mu.Unlock()
+
+	return source.Versions(ctx, lib)
+}
+
+// tagClient fetches tag lists from a paginated tags API. Pages are remembered with their ETag
+// and revalidated with If-None-Match, so unchanged tag lists cost a 304 (which GitHub does not
+// count against the rate limit).
+type tagClient struct {
+	api    string      // API name used in errors (e.g. "GitHub")
+	header http.Header // Sent with every request
+	client *http.Client
+
+	mu    sync.Mutex
+	pages map[string]tagPage // keyed by page URL
+}
+
+// tagPage is a cached page of a tags API
+type tagPage struct {
+	etag string
+	tags []string
+	next string
+}
+
+// newTagClient creates a tag client sending header with every request
+func newTagClient(api string, header http.Header) *tagClient {
+	// Tags APIs require a User-Agent
+	header.Set("User-Agent", "go-starter-api")
+	return &tagClient{
+		api:    api,
+		header: header,
+		client: &http.Client{Timeout: 5 * time.Second},
+		pages:  make(map[string]tagPage),
+	}
+}
+
+// tags fetches every tag name starting at pageURL, following the pagination links. Links to another
+// scheme or host than pageURL are refused, so the API token is only sent where it belongs.
+func (c *tagClient) tags(ctx context.Context, pageURL string) ([]string, error) {
+	start, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for page := 0; pageURL != "" && page < maxTagPages; page++ {
+		p, err := c.page(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, p.tags...)
+		if p.next == "" {
+			break
+		}
+
+		next, err := url.Parse(pageURL)
+		if err == nil {
+			next, err = next.Parse(p.next)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s API returned an invalid next page link: %w", c.api, err)
+		}
+		if next.Scheme != start.Scheme || next.Host != start.Host {
+			return nil, fmt.Errorf("%s API links to a next page on %s://%s, not following it", c.api, next.Scheme, next.Host)
+		}
+		pageURL = next.String()
+	}
+
+	return names, nil
+}
+
+// page fetches one page of tags, revalidating the cached copy when there is one
+func (c *tagClient) page(ctx context.Context, pageURL string) (tagPage, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return tagPage{}, err
+	}
+	req.Header = c.header.Clone()
+
+	c.mu.Lock()
+	cached, ok := c.pages[pageURL]
+	c.mu.Unlock()
+	if ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return tagPage{}, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return tagPage{}, fmt.Errorf("%s API returned %s", c.api, resp.Status)
+	}
+
+	// GitHub, GitLab and Gitea all list tags as objects with a name
+	var tags []GitHubTag
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return tagPage{}, err
+	}
+
+	p := tagPage{
+		etag: resp.Header.Get("ETag"),
+		next: nextPageURL(resp.Header.Get("Link")),
+	}
+	for _, tag := range tags {
+		p.tags = append(p.tags, tag.Name)
+	}
+
+	if p.etag != "" {
+		c.mu.Lock()
+		c.pages[pageURL] = p
+		c.mu.Unlock()
+	}
+	return p, nil
+}
+
+// nextPageURL returns the rel="next" target of a Link header, or "" on the last page
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// repoPath returns the repository path of a library's RepoURL (e.g. "group/subgroup/repo")
+func repoPath(lib types.Library) (string, error) {
+	u, err := url.Parse(lib.RepoURL)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if !strings.Contains(path, "/") {
+		return "", fmt.Errorf("repository URL %q has no owner and repository name", lib.RepoURL)
+	}
+	return path, nil
+}
@@ -0,0 +1,161 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// fakeForge serves the GitLab and Gitea tags APIs of one repository host, requiring a token
+func fakeForge(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+
+	reply := func(w http.ResponseWriter, tags ...string) {
+		var body []GitHubTag
+		for _, tag := range tags {
+			body = append(body, GitHubTag{Name: tag})
+		}
+		json.NewEncoder(w).Encode(body)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/{project}/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.PathValue("project") != "acme/platform/go-queue" || r.URL.Query().Get("per_page") != "100" {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, "v0.2.0", "v0.3.0-beta.1")
+	})
+	mux.HandleFunc("GET /api/v1/repos/{owner}/{repo}/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.PathValue("owner") != "acme" || r.PathValue("repo") != "go-cron" || r.URL.Query().Get("limit") != "50" {
+			http.NotFound(w, r)
+			return
+		}
+		reply(w, "v1.4.0")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitLabSourceVersions(t *testing.T) {
+	server := fakeForge(t, "secret")
+
+	lib := types.Library{RepoURL: server.URL + "/acme/platform/go-queue"}
+	versions, err := NewGitLabSource(server.URL+"/api/v4", "secret").Versions(context.Background(), lib)
+	if err != nil || !slices.Equal(versions, []string{"v0.2.0", "v0.3.0-beta.1"}) {
+		t.Errorf("Versions = %v, %v", versions, err)
+	}
+}
+
+func TestGiteaSourceVersions(t *testing.T) {
+	server := fakeForge(t, "secret")
+
+	lib := types.Library{RepoURL: server.URL + "/acme/go-cron"}
+	versions, err := NewGiteaSource(server.URL+"/api/v1", "secret").Versions(context.Background(), lib)
+	if err != nil || !slices.Equal(versions, []string{"v1.4.0"}) {
+		t.Errorf("Versions = %v, %v", versions, err)
+	}
+
+	lib.RepoURL = server.URL + "/acme/platform/go-cron"
+	if _, err := NewGiteaSource(server.URL+"/api/v1", "secret").Versions(context.Background(), lib); err == nil {
+		t.Error("expected an error for a nested repository path")
+	}
+}
+
+func TestVCSSourceVersions(t *testing.T) {
+	server := fakeForge(t, "secret")
+	host := mustParseURL(t, server.URL).Host
+
+	source := NewVCSSource(map[string]string{host: "secret"})
+
+	tests := []struct {
+		lib  types.Library
+		want string
+	}{
+		{types.Library{RepoURL: server.URL + "/acme/platform/go-queue", Provider: ProviderGitLab}, "v0.2.0"},
+		{types.Library{RepoURL: server.URL + "/acme/go-cron", Provider: ProviderGitea}, "v1.4.0"},
+		{types.Library{RepoURL: "https://git.acme.dev/acme/go-cron", APIURL: server.URL + "/api/v1", Provider: ProviderGitea}, ""},
+	}
+	for _, tt := range tests {
+		versions, err := source.Versions(context.Background(), tt.lib)
+		if tt.want == "" {
+			// Tokens are only sent to the host they belong to
+			if err == nil {
+				t.Errorf("%s: token of %s sent to another host", tt.lib.RepoURL, host)
+			}
+			continue
+		}
+		if err != nil || LatestVersions(versions).Stable != tt.want {
+			t.Errorf("%s: Versions = %v, %v; want %s", tt.lib.RepoURL, versions, err, tt.want)
+		}
+	}
+
+	// Nor to an API on another host than the repository
+	var leaked string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+		w.Write([]byte("[]"))
+	}))
+	defer other.Close()
+	lib := types.Library{RepoURL: server.URL + "/acme/go-cron", APIURL: other.URL + "/api/v1", Provider: ProviderGitea}
+	if source.Versions(context.Background(), lib); leaked != "" {
+		t.Errorf("token of %s sent to %s: %q", host, other.URL, leaked)
+	}
+
+	if _, err := source.Versions(context.Background(), types.Library{RepoURL: "https://git.acme.dev/acme/go-cron"}); err == nil {
+		t.Error("expected an error for a host without a provider")
+	}
+}
+
+// mustParseURL parses a URL, failing the test on error
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestTagClientCrossHostLink(t *testing.T) {
+	var leaked string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+		w.Write([]byte("[]"))
+	}))
+	defer other.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/tags?page=2>; rel="next"`, other.URL))
+		w.Write([]byte(`[{"name": "v1.0.0"}]`))
+	}))
+	defer api.Close()
+
+	header := http.Header{}
+	header.Set("Authorization", "token secret")
+	_, err := newTagClient("Gitea", header).tags(context.Background(), api.URL+"/tags")
+	if err == nil || !strings.Contains(err.Error(), "not following it") {
+		t.Errorf("tags error = %v, want a refused link", err)
+	}
+	if leaked != "" {
+		t.Errorf("token sent to %s: %q", other.URL, leaked)
+	}
+}
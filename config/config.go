@@ -0,0 +1,280 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/cors"
+	"github.com/goccy/go-yaml"
+)
+
+// Config is the configuration of the API server. Every setting is read, from lowest to highest
+// precedence, from the defaults, the YAML file, the environment and the command line.
+type Config struct {
+	File string `json:"-"` // YAML file the configuration was read from, if any
+
+	Server  ServerConfig  `json:"server"`
+	Log     LogConfig     `json:"log"`
+	CORS    cors.Options  `json:"cors"`
+	Catalog CatalogConfig `json:"catalog"`
+	Jobs    JobsConfig    `json:"jobs"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Port            int           `json:"port"`            // Listening port
+	Mode            string        `json:"mode"`            // Gin mode: release, debug or test
+	ReadTimeout     time.Duration `json:"readTimeout"`     // Time to read a request, body included
+	WriteTimeout    time.Duration `json:"writeTimeout"`    // Time to handle a request and write its response
+	IdleTimeout     time.Duration `json:"idleTimeout"`     // How long idle keep-alive connections stay open
+	ShutdownTimeout time.Duration `json:"shutdownTimeout"` // How long running jobs and requests get to finish on shutdown
+}
+
+// LogConfig configures the logger
+type LogConfig struct {
+	Level  string `json:"level"`  // debug, info, warn or error
+	Format string `json:"format"` // json or text
+}
+
+// CatalogConfig configures the library catalog
+type CatalogConfig struct {
+	Source   string        `json:"source"`   // Where versions come from: vcs (repository hosts) or proxy (module proxy)
+	Manifest string        `json:"manifest"` // Manifest file or directory merged over the built-in one
+	TTL      time.Duration `json:"ttl"`      // How long fetched versions are fresh
+}
+
+// JobsConfig configures the generation job queue
+type JobsConfig struct {
+	Workers   int           `json:"workers"`   // Jobs run at the same time
+	QueueSize int           `json:"queueSize"` // Jobs waiting for a worker before new ones are refused
+	TTL       time.Duration `json:"ttl"`       // How long finished jobs are kept
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			Mode:            "release",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    2 * time.Minute,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Log:     LogConfig{Level: "info", Format: "json"},
+		CORS:    cors.DefaultOptions(),
+		Catalog: CatalogConfig{Source: "vcs", TTL: 15 * time.Minute},
+		Jobs:    JobsConfig{Workers: 2, QueueSize: 100, TTL: time.Hour},
+	}
+}
+
+// setting is a configuration value that can be set from the environment and the command line
+type setting struct {
+	key   string // Path in the YAML file
+	flag  string
+	env   string
+	usage string
+	field func(c *Config) any // Pointer to the value: *string, *int, *bool, *time.Duration or *[]string
+}
+
+var settings = []setting{
+	{"server.port", "port", "PORT", "listening port", func(c *Config) any { return &c.Server.Port }},
+	{"server.mode", "mode", "GIN_MODE", "gin mode: release, debug or test", func(c *Config) any { return &c.Server.Mode }},
+	{"server.readTimeout", "read-timeout", "SERVER_READ_TIMEOUT", "time to read a request", func(c *Config) any { return &c.Server.ReadTimeout }},
+	{"server.writeTimeout", "write-timeout", "SERVER_WRITE_TIMEOUT", "time to handle a request and write its response", func(c *Config) any { return &c.Server.WriteTimeout }},
+	{"server.idleTimeout", "idle-timeout", "SERVER_IDLE_TIMEOUT", "how long idle keep-alive connections stay open", func(c *Config) any { return &c.Server.IdleTimeout }},
+	{"server.shutdownTimeout", "shutdown-timeout", "SERVER_SHUTDOWN_TIMEOUT", "how long running jobs and requests get to finish on shutdown", func(c *Config) any { return &c.Server.ShutdownTimeout }},
+	{"log.level", "log-level", "LOG_LEVEL", "log level: debug, info, warn or error", func(c *Config) any { return &c.Log.Level }},
+	{"log.format", "log-format", "LOG_FORMAT", "log format: json or text", func(c *Config) any { return &c.Log.Format }},
+	{"cors.allowedOrigins", "cors-origins", "CORS_ALLOWED_ORIGINS", "comma-separated origins allowed to call the API: exact, https://*.example.com for subdomains or * for any", func(c *Config) any { return &c.CORS.AllowedOrigins }},
+	{"cors.allowedMethods", "cors-methods", "CORS_ALLOWED_METHODS", "comma-separated methods allowed in cross-origin requests", func(c *Config) any { return &c.CORS.AllowedMethods }},
+	{"cors.allowedHeaders", "cors-headers", "CORS_ALLOWED_HEADERS", "comma-separated request headers allowed in cross-origin requests, * for any", func(c *Config) any { return &c.CORS.AllowedHeaders }},
+	{"cors.exposedHeaders", "cors-exposed-headers", "CORS_EXPOSED_HEADERS", "comma-separated response headers readable by cross-origin pages", func(c *Config) any { return &c.CORS.ExposedHeaders }},
+	{"cors.maxAge", "cors-max-age", "CORS_MAX_AGE", "how long browsers may cache a preflight response", func(c *Config) any { return &c.CORS.MaxAge }},
+	{"cors.allowCredentials", "cors-credentials", "CORS_ALLOW_CREDENTIALS", "allow cookies and HTTP authentication in cross-origin requests", func(c *Config) any { return &c.CORS.AllowCredentials }},
+	{"catalog.source", "catalog-source", "CATALOG_SOURCE", "library version source: vcs or proxy", func(c *Config) any { return &c.Catalog.Source }},
+	{"catalog.manifest", "catalog-manifest", "CATALOG_MANIFEST", "manifest file or directory merged over the built-in libraries", func(c *Config) any { return &c.Catalog.Manifest }},
+	{"catalog.ttl", "catalog-ttl", "CATALOG_TTL", "how long fetched library versions are fresh", func(c *Config) any { return &c.Catalog.TTL }},
+	{"jobs.workers", "jobs-workers", "JOBS_WORKERS", "generation jobs run at the same time", func(c *Config) any { return &c.Jobs.Workers }},
+	{"jobs.queueSize", "jobs-queue-size", "JOBS_QUEUE_SIZE", "generation jobs waiting before new ones are refused", func(c *Config) any { return &c.Jobs.QueueSize }},
+	{"jobs.ttl", "jobs-ttl", "JOBS_TTL", "how long finished jobs are kept", func(c *Config) any { return &c.Jobs.TTL }},
+}
+
+// set parses a value of the setting into c
+func (s setting) set(c *Config, value string) error {
+	var err error
+	switch field := s.field(c).(type) {
+	case *string:
+		*field = value
+	case *int:
+		*field, err = strconv.Atoi(value)
+	case *bool:
+		*field, err = strconv.ParseBool(value)
+	case *time.Duration:
+		*field, err = time.ParseDuration(value)
+	case *[]string:
+		*field = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*field = append(*field, item)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: invalid value %q", s.key, value)
+	}
+	return nil
+}
+
+// get formats the value of the setting in c
+func (s setting) get(c *Config) string {
+	switch field := s.field(c).(type) {
+	case *string:
+		return *field
+	case *int:
+		return strconv.Itoa(*field)
+	case *bool:
+		return strconv.FormatBool(*field)
+	case *time.Duration:
+		return field.String()
+	case *[]string:
+		return strings.Join(*field, ",")
+	}
+	return ""
+}
+
+// Load reads the configuration from the YAML file named by -config or CONFIG_FILE, the environment
+// and the command line arguments, and validates it. It returns flag.ErrHelp when -help was asked for.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagValues []flagValue
+
+	flags := flag.NewFlagSet("go-starter-api", flag.ContinueOnError)
+	file := flags.String("config", getenv("CONFIG_FILE"), "YAML configuration `file` (env CONFIG_FILE)")
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.get(Default()))
+		collect := func(value string) error {
+			flagValues = append(flagValues, flagValue{s, value})
+			return nil
+		}
+		if _, ok := s.field(Default()).(*bool); ok {
+			flags.BoolFunc(s.flag, usage, collect)
+		} else {
+			flags.Func(s.flag, usage, collect)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	c := Default()
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalWithOptions(data, c, yaml.Strict()); err != nil {
+			return nil, fmt.Errorf("%s: %w", *file, err)
+		}
+		c.File = *file
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.set(c, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for _, v := range flagValues {
+		if err := v.setting.set(c, v.value); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", v.setting.flag, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate reports every invalid setting
+func (c *Config) Validate() error {
+	var errs []error
+	oneOf := func(key, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			errs = append(errs, fmt.Errorf("%s: %q is not one of %s", key, value, strings.Join(allowed, ", ")))
+		}
+	}
+	positive := func(key string, value int64) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s: must be positive", key))
+		}
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: %d is not a port number", c.Server.Port))
+	}
+	oneOf("server.mode", c.Server.Mode, "release", "debug", "test")
+	positive("server.readTimeout", int64(c.Server.ReadTimeout))
+	positive("server.writeTimeout", int64(c.Server.WriteTimeout))
+	positive("server.idleTimeout", int64(c.Server.IdleTimeout))
+	positive("server.shutdownTimeout", int64(c.Server.ShutdownTimeout))
+	oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	oneOf("log.format", c.Log.Format, "json", "text")
+	if _, err := cors.New(c.CORS); err != nil {
+		errs = append(errs, fmt.Errorf("cors: %w", err))
+	}
+	oneOf("catalog.source", c.Catalog.Source, "vcs", "proxy")
+	positive("catalog.ttl", int64(c.Catalog.TTL))
+	positive("jobs.workers", int64(c.Jobs.Workers))
+	positive("jobs.queueSize", int64(c.Jobs.QueueSize))
+	positive("jobs.ttl", int64(c.Jobs.TTL))
+	return errors.Join(errs...)
+}
+
+// Addr returns the address the server listens on
+func (c *Config) Addr() string {
+	return ":" + strconv.Itoa(c.Server.Port)
+}
+
+// Logger returns the logger configuration
+func (c *Config) Logger() *logger.Config {
+	config := logger.DefaultConfig()
+	config.Level = map[string]logger.Level{
+		"debug": logger.LevelDebug,
+		"info":  logger.LevelInfo,
+		"warn":  logger.LevelWarn,
+		"error": logger.LevelError,
+	}[c.Log.Level]
+	if c.Log.Format == "text" {
+		config.Format = logger.FormatText
+	}
+	return config
+}
+
+// LogFields returns the effective configuration as log fields, one per setting
+func (c *Config) LogFields() []logger.Field {
+	fields := []logger.Field{logger.String("file", c.File)}
+	for _, s := range settings {
+		fields = append(fields, logger.String(s.key, s.get(c)))
+	}
+	return fields
+}
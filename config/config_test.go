@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(file, []byte(`
+server:
+  port: 9000
+  mode: debug
+log:
+  level: debug
+cors:
+  allowedOrigins: [https://app.example.com]
+jobs:
+  workers: 4
+  ttl: 30m
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"CONFIG_FILE":     file,
+		"PORT":            "9100",
+		"LOG_FORMAT":      "text",
+		"JOBS_QUEUE_SIZE": "10",
+	}
+	args := []string{"-port", "9200", "-cors-origins", "https://a.example.com, https://b.example.com", "-cors-credentials"}
+	c, err := Load(args, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := Default()
+	want.File = file
+	want.Server.Port, want.Server.Mode = 9200, "debug"                                    // flag over env over file
+	want.Log = LogConfig{Level: "debug", Format: "text"}                                  // file and env
+	want.CORS.AllowedOrigins = []string{"https://a.example.com", "https://b.example.com"} // flag over file
+	want.CORS.AllowCredentials = true                                                     // flag
+	want.Jobs = JobsConfig{Workers: 4, QueueSize: 10, TTL: 30 * time.Minute}              // file, env and default
+	if c.File != want.File || c.Server != want.Server || c.Log != want.Log || c.Catalog != want.Catalog || c.Jobs != want.Jobs ||
+		!slices.Equal(c.CORS.AllowedOrigins, want.CORS.AllowedOrigins) || c.CORS.AllowCredentials != want.CORS.AllowCredentials {
+		t.Errorf("Load = %+v, want %+v", c, want)
+	}
+	if c.Addr() != ":9200" {
+		t.Errorf("Addr = %q", c.Addr())
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, err := Load(nil, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Addr() != ":8080" || c.Server.Mode != "release" || !slices.Equal(c.CORS.AllowedOrigins, []string{"*"}) {
+		t.Errorf("default config = %+v", c)
+	}
+}
+
+// discardStderr silences the usage printed on flag errors for the rest of the test
+func discardStderr(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = devNull
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		devNull.Close()
+	})
+}
+
+func TestLoadErrors(t *testing.T) {
+	discardStderr(t)
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("server:\n  prot: 9000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want []string // in the error message
+	}{
+		{"unknown file key", []string{"-config", file}, nil, []string{"prot"}},
+		{"missing file", []string{"-config", file + ".missing"}, nil, []string{"no such file"}},
+		{"bad values", nil, map[string]string{"PORT": "http", "JOBS_TTL": "soon"}, []string{"PORT: server.port", "JOBS_TTL: jobs.ttl"}},
+		{"invalid settings", []string{"-port", "70000", "-mode", "prod", "-jobs-workers", "0", "-shutdown-timeout", "-1s", "-cors-origins", ","}, nil, []string{
+			"server.port", "server.mode", "jobs.workers", "server.shutdownTimeout", "cors: allowedOrigins",
+		}},
+		{"catalog source", nil, map[string]string{"CATALOG_SOURCE": "github"}, []string{`catalog.source: "github" is not one of vcs, proxy`}},
+		{"unknown flag", []string{"-verbose"}, nil, []string{"-verbose"}},
+		{"arguments", []string{"serve"}, nil, []string{"unexpected arguments: serve"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.args, func(key string) string { return tt.env[key] })
+			if err == nil {
+				t.Fatal("Load succeeded")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	discardStderr(t)
+	if _, err := Load([]string{"-help"}, func(string) string { return "" }); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Load(-help) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestLogFields(t *testing.T) {
+	fields := Default().LogFields()
+	values := make(map[string]any)
+	for _, field := range fields {
+		values[field.Key] = field.Value
+	}
+	if values["server.port"] != "8080" || values["catalog.ttl"] != "15m0s" || values["cors.allowedOrigins"] != "*" {
+		t.Errorf("fields = %v", values)
+	}
+}
@@ -0,0 +1,220 @@
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Options configures a CORS Policy
+type Options struct {
+	AllowedOrigins   []string      `json:"allowedOrigins"`   // Exact origins, "*" for any, or "https://*.example.com" for its subdomains
+	AllowedMethods   []string      `json:"allowedMethods"`   // Methods allowed in cross-origin requests
+	AllowedHeaders   []string      `json:"allowedHeaders"`   // Request headers allowed in cross-origin requests, "*" for any
+	ExposedHeaders   []string      `json:"exposedHeaders"`   // Response headers the browser lets scripts read
+	MaxAge           time.Duration `json:"maxAge"`           // How long browsers may cache a preflight response
+	AllowCredentials bool          `json:"allowCredentials"` // Whether requests may carry cookies and HTTP authentication
+}
+
+// DefaultOptions allows any origin to call the API without credentials, and to read the
+// download headers: file name, archive checksum, cache status and job location
+func DefaultOptions() Options {
+	return Options{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Origin", "Content-Type", "Accept"},
+		ExposedHeaders: []string{"Content-Disposition", "ETag", "X-Archive-SHA256", "X-Cache", "Location"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// Policy answers preflight requests and adds CORS headers to the responses of allowed origins
+type Policy struct {
+	opts      Options
+	anyOrigin bool
+	origins   map[string]bool // exact origins, lowercase
+	patterns  []originPattern
+	anyHeader bool
+
+	methods string
+	headers string
+	exposed string
+	maxAge  string
+}
+
+// originPattern is the text around the "*" of an origin like "https://*.example.com"
+type originPattern struct {
+	prefix, suffix string
+}
+
+// matches reports whether an origin is a subdomain of the pattern
+func (p originPattern) matches(origin string) bool {
+	if len(origin) <= len(p.prefix)+len(p.suffix) || !strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	sub := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+	return !strings.ContainsAny(sub, ":/@")
+}
+
+// New creates a Policy, reporting every invalid option
+func New(opts Options) (*Policy, error) {
+	p := &Policy{
+		opts:      opts,
+		origins:   make(map[string]bool),
+		anyHeader: slices.Contains(opts.AllowedHeaders, "*"),
+		headers:   strings.Join(opts.AllowedHeaders, ", "),
+		exposed:   strings.Join(opts.ExposedHeaders, ", "),
+	}
+
+	var errs []error
+	if len(opts.AllowedOrigins) == 0 {
+		errs = append(errs, errors.New("allowedOrigins: at least one origin is required"))
+	}
+	for _, origin := range opts.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			pattern, err := parsePattern(origin)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("allowedOrigins: %w", err))
+			}
+			p.patterns = append(p.patterns, pattern)
+		default:
+			if err := checkOrigin(origin); err != nil {
+				errs = append(errs, fmt.Errorf("allowedOrigins: %w", err))
+			}
+			p.origins[origin] = true
+		}
+	}
+	if p.anyOrigin && opts.AllowCredentials {
+		// Browsers refuse "*" with credentials, and allowing every origin to send them is unsafe
+		errs = append(errs, errors.New("allowedOrigins: * cannot be used with allowCredentials, list the origins"))
+	}
+
+	if len(opts.AllowedMethods) == 0 {
+		errs = append(errs, errors.New("allowedMethods: at least one method is required"))
+	}
+	methods := make([]string, len(opts.AllowedMethods))
+	for i, method := range opts.AllowedMethods {
+		methods[i] = strings.ToUpper(method)
+	}
+	p.methods = strings.Join(methods, ", ")
+
+	if opts.MaxAge < 0 {
+		errs = append(errs, errors.New("maxAge: must not be negative"))
+	}
+	if seconds := int(opts.MaxAge / time.Second); seconds > 0 {
+		p.maxAge = strconv.Itoa(seconds)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parsePattern parses an origin pattern like "https://*.example.com"
+func parsePattern(pattern string) (originPattern, error) {
+	scheme, rest, ok := strings.Cut(pattern, "://*.")
+	if !ok || scheme == "" || strings.Contains(rest, "*") || checkOrigin(scheme+"://"+rest) != nil {
+		return originPattern{}, fmt.Errorf("%q is not a pattern like https://*.example.com", pattern)
+	}
+	return originPattern{prefix: scheme + "://", suffix: "." + rest}, nil
+}
+
+// checkOrigin reports origins that are not a scheme, host and optional port
+func checkOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%q is not an origin like https://app.example.com", origin)
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether the policy allows an origin
+func (p *Policy) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	if p.anyOrigin || p.origins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns the middleware applying the policy. It answers preflight requests itself,
+// with 204 for allowed origins and 403 for others.
+func (p *Policy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		header := c.Writer.Header()
+		if !p.anyOrigin {
+			header.Add("Vary", "Origin")
+		}
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			// Not a cross-origin request
+			c.Next()
+			return
+		}
+		if !p.AllowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatusJSON(403, types.ErrorResponse{Error: types.APIError{
+					Code:    types.CodeOriginNotAllowed,
+					Message: fmt.Sprintf("Origin %s is not allowed", origin),
+				}})
+				return
+			}
+			// Served without CORS headers, so the browser keeps the response from the page
+			c.Next()
+			return
+		}
+
+		if p.anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.opts.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if p.exposed != "" {
+				header.Set("Access-Control-Expose-Headers", p.exposed)
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", p.methods)
+		if requested := c.GetHeader("Access-Control-Request-Headers"); p.anyHeader && requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		} else if p.headers != "" {
+			header.Set("Access-Control-Allow-Headers", p.headers)
+		}
+		if p.maxAge != "" {
+			header.Set("Access-Control-Max-Age", p.maxAge)
+		}
+		c.AbortWithStatus(204)
+	}
+}
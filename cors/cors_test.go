@@ -0,0 +1,175 @@
+package cors
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter returns a router with the policy and a POST endpoint
+func newTestRouter(t *testing.T, opts Options) *gin.Engine {
+	t.Helper()
+	policy, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	r.Use(policy.Handler())
+	r.POST("/api/generate", func(c *gin.Context) {
+		c.Header("X-Archive-SHA256", "abc")
+		c.String(200, "ok")
+	})
+	return r
+}
+
+func TestAllowsOrigin(t *testing.T) {
+	policy, err := New(Options{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org", "http://localhost:3000"},
+		AllowedMethods: []string{"GET"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for origin, want := range map[string]bool{
+		"https://app.example.com":       true,
+		"HTTPS://App.Example.com":       true,
+		"http://app.example.com":        false,
+		"https://api.example.com":       false,
+		"https://a.example.org":         true,
+		"https://a.b.example.org":       true,
+		"https://example.org":           false,
+		"https://.example.org":          false,
+		"https://evil.com/.example.org": false,
+		"https://a.example.org:8443":    false,
+		"https://evilexample.org":       false,
+		"http://localhost:3000":         true,
+		"http://localhost:3001":         false,
+		"null":                          false,
+	} {
+		if got := policy.AllowsOrigin(origin); got != want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"no origins", Options{AllowedMethods: []string{"GET"}}, "at least one origin"},
+		{"any origin with credentials", Options{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowCredentials: true}, "cannot be used with allowCredentials"},
+		{"origin with path", Options{AllowedOrigins: []string{"https://app.example.com/"}, AllowedMethods: []string{"GET"}}, "not an origin"},
+		{"bare host", Options{AllowedOrigins: []string{"app.example.com"}, AllowedMethods: []string{"GET"}}, "not an origin"},
+		{"pattern", Options{AllowedOrigins: []string{"https://app.*.com"}, AllowedMethods: []string{"GET"}}, "not a pattern"},
+		{"no methods", Options{AllowedOrigins: []string{"*"}}, "at least one method"},
+		{"negative max age", Options{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, MaxAge: -time.Second}, "maxAge"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	opts := DefaultOptions()
+	opts.AllowedOrigins = []string{"https://*.example.com"}
+	opts.AllowCredentials = true
+	router := newTestRouter(t, opts)
+
+	serve := func(method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/generate", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		rec := serve("OPTIONS", "https://app.example.com", map[string]string{
+			"Access-Control-Request-Method":  "POST",
+			"Access-Control-Request-Headers": "content-type",
+		})
+		want := map[string]string{
+			"Access-Control-Allow-Origin":      "https://app.example.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET, POST",
+			"Access-Control-Allow-Headers":     "Origin, Content-Type, Accept",
+			"Access-Control-Max-Age":           "600",
+		}
+		if rec.Code != 204 {
+			t.Errorf("status = %d, want 204", rec.Code)
+		}
+		for name, value := range want {
+			if got := rec.Header().Get(name); got != value {
+				t.Errorf("%s = %q, want %q", name, got, value)
+			}
+		}
+		if vary := strings.Join(rec.Header().Values("Vary"), ", "); vary != "Origin, Access-Control-Request-Method, Access-Control-Request-Headers" {
+			t.Errorf("Vary = %q", vary)
+		}
+	})
+
+	t.Run("preflight of another origin", func(t *testing.T) {
+		rec := serve("OPTIONS", "https://example.net", map[string]string{"Access-Control-Request-Method": "POST"})
+		if rec.Code != 403 || rec.Header().Get("Access-Control-Allow-Origin") != "" || !strings.Contains(rec.Body.String(), "origin_not_allowed") {
+			t.Errorf("preflight = %d %v %s", rec.Code, rec.Header(), rec.Body)
+		}
+	})
+
+	t.Run("request", func(t *testing.T) {
+		rec := serve("POST", "https://app.example.com", nil)
+		if rec.Code != 200 || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+			!strings.Contains(rec.Header().Get("Access-Control-Expose-Headers"), "X-Archive-SHA256") {
+			t.Errorf("request = %d %v", rec.Code, rec.Header())
+		}
+	})
+
+	t.Run("request of another origin", func(t *testing.T) {
+		rec := serve("POST", "https://example.net", nil)
+		if rec.Code != 200 || rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Access-Control-Expose-Headers") != "" {
+			t.Errorf("request = %d %v", rec.Code, rec.Header())
+		}
+	})
+
+	t.Run("same origin", func(t *testing.T) {
+		rec := serve("POST", "", nil)
+		if rec.Code != 200 || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("request = %d %v", rec.Code, rec.Header())
+		}
+	})
+}
+
+func TestHandlerAnyOrigin(t *testing.T) {
+	opts := DefaultOptions()
+	opts.AllowedHeaders = []string{"*"}
+	router := newTestRouter(t, opts)
+
+	req := httptest.NewRequest("OPTIONS", "/api/generate", nil)
+	req.Header.Set("Origin", "https://anywhere.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "content-type, x-request-id")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != 204 || rec.Header().Get("Access-Control-Allow-Origin") != "*" ||
+		rec.Header().Get("Access-Control-Allow-Headers") != "content-type, x-request-id" ||
+		rec.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("preflight = %d %v", rec.Code, rec.Header())
+	}
+}
@@ -26,12 +26,14 @@ const docTemplate = `{
     "paths": {
         "/generate": {
             "post": {
-                "description": "Generates a new Go project with selected libraries and configuration, returns a ZIP file",
+                "description": "Generates a new Go project with selected libraries and configuration, returns an archive.\nThe format option selects zip, tar.gz or tar.zst; without it the Accept header picks one, defaulting to zip.\ntar archives keep file modes. Archives are reproducible: identical configurations give identical bytes,\nwhose SHA-256 is sent as ETag and X-Archive-SHA256, and are served from a cache after the first request.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/zip"
+                    "application/zip",
+                    "application/gzip",
+                    "application/zstd"
                 ],
                 "tags": [
                     "Generator"
@@ -50,21 +52,206 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "ZIP file download",
+                        "description": "Archive download",
                         "schema": {
                             "type": "file"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Quoted SHA-256 of the archive"
+                            },
+                            "X-Archive-SHA256": {
+                                "type": "string",
+                                "description": "Hex SHA-256 of the archive"
+                            },
+                            "X-Cache": {
+                                "type": "string",
+                                "description": "HIT when served from the archive cache, else MISS"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "invalid_body, validation_failed or rule_violation, with details per field",
                         "schema": {
-                            "$ref": "#/definitions/types.GenerateResponse"
+                            "$ref": "#/definitions/types.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "generation_failed or archive_failed",
                         "schema": {
-                            "$ref": "#/definitions/types.GenerateResponse"
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "shutting_down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "post": {
+                "description": "Validates the configuration like /generate and queues its generation. Poll the returned job\nfor its status and download its archive from the artifact URL once it succeeded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Queue a project generation",
+                "parameters": [
+                    {
+                        "description": "Project configuration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.GenerateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.JobResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_body, validation_failed or rule_violation, with details per field",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "queue_full or shutting_down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Returns the status, progress and error of a job, and its artifact once it succeeded.\nFinished jobs are kept for an hour by default.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get a generation job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/artifact": {
+            "get": {
+                "description": "Returns the archive of a succeeded job, with its SHA-256 as ETag and X-Archive-SHA256",
+                "produces": [
+                    "application/zip",
+                    "application/gzip",
+                    "application/zstd"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Download the archive of a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Archive download",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "job_pending or job_failed",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/events": {
+            "get": {
+                "description": "Streams the job as Server-Sent Events: one event with its current state, then one per update until it finished.\nThe event name is the job status (queued, running, succeeded or failed) and the data is the job as JSON;\nrunning events carry the generator phase in progress, failed events the phase that failed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Follow a generation job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One event per update",
+                        "schema": {
+                            "$ref": "#/definitions/types.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
                         }
                     }
                 }
@@ -72,7 +259,7 @@ const docTemplate = `{
         },
         "/libraries": {
             "get": {
-                "description": "Returns a list of all 10 production Go libraries with their metadata",
+                "description": "Returns the built-in production Go libraries and those of registered catalog sources with their metadata",
                 "consumes": [
                     "application/json"
                 ],
@@ -83,12 +270,83 @@ const docTemplate = `{
                     "Libraries"
                 ],
                 "summary": "Get all available libraries",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Use the latest pre-release as version when it is newer than the latest stable tag",
+                        "name": "prerelease",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the libraries of this catalog source (e.g. builtin)",
+                        "name": "source",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.LibrariesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_query",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preview": {
+            "post": {
+                "description": "Generates a project like /generate and returns its file tree with sizes and, with includeContents, the content of every file",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Generator"
+                ],
+                "summary": "Preview a generated Go project",
+                "parameters": [
+                    {
+                        "description": "Project configuration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.PreviewRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "success, data (array of Library), count",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.PreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_body, validation_failed or rule_violation, with details per field",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "generation_failed",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "shutting_down",
+                        "schema": {
+                            "$ref": "#/definitions/types.ErrorResponse"
                         }
                     }
                 }
@@ -96,6 +354,32 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "types.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "details": {
+                    "description": "Every problem found, when there are several",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ErrorDetail"
+                    }
+                },
+                "field": {
+                    "description": "JSON path of the field at fault, if there is one",
+                    "type": "string",
+                    "example": "name"
+                },
+                "message": {
+                    "description": "English message, for logs and fallback display",
+                    "type": "string",
+                    "example": "Project name is required"
+                }
+            }
+        },
         "types.DatabaseConfig": {
             "type": "object",
             "properties": {
@@ -105,9 +389,50 @@ const docTemplate = `{
                 }
             }
         },
+        "types.ErrorDetail": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "unknown_library"
+                },
+                "field": {
+                    "description": "JSON path of the field at fault (e.g. \"libraries[2]\", \"versions.go-auth\")",
+                    "type": "string",
+                    "example": "libraries[2]"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Unknown library \"go-orm\""
+                },
+                "params": {
+                    "description": "Values used in the message, for localized messages",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "types.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/types.APIError"
+                },
+                "success": {
+                    "description": "Always false",
+                    "type": "boolean"
+                }
+            }
+        },
         "types.GenerateRequest": {
             "type": "object",
             "properties": {
+                "addImplied": {
+                    "description": "Add the libraries implied by the selected ones",
+                    "type": "boolean"
+                },
                 "database": {
                     "$ref": "#/definitions/types.DatabaseConfig"
                 },
@@ -115,6 +440,10 @@ const docTemplate = `{
                     "description": "\"railway\", \"local\", \"docker\"",
                     "type": "string"
                 },
+                "format": {
+                    "description": "Archive format: \"zip\", \"tar.gz\" or \"tar.zst\"; negotiated from Accept when empty",
+                    "type": "string"
+                },
                 "libraries": {
                     "type": "array",
                     "items": {
@@ -127,27 +456,325 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "prerelease": {
+                    "description": "Use pre-release library versions newer than the latest stable",
+                    "type": "boolean"
+                },
+                "resolveDependencies": {
+                    "description": "Tidy go.mod and write go.sum through the module proxy",
+                    "type": "boolean"
+                },
                 "structure": {
                     "description": "\"simple\" or \"standard\"",
                     "type": "string"
+                },
+                "verify": {
+                    "description": "Type-check the generated code before packaging",
+                    "type": "boolean"
+                },
+                "versions": {
+                    "description": "Per-library version overrides (e.g. {\"go-auth\": \"v1.2.0\"})",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "types.GenerateResponse": {
+        "types.Job": {
             "type": "object",
             "properties": {
-                "downloadUrl": {
+                "artifact": {
+                    "description": "The generated archive, once the job succeeded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.JobArtifact"
+                        }
+                    ]
+                },
+                "createdAt": {
                     "type": "string"
                 },
                 "error": {
+                    "description": "Why the job failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.APIError"
+                        }
+                    ]
+                },
+                "finishedAt": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/types.JobProgress"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "\"queued\", \"running\", \"succeeded\" or \"failed\"",
+                    "type": "string",
+                    "example": "running"
+                }
+            }
+        },
+        "types.JobArtifact": {
+            "type": "object",
+            "properties": {
+                "contentType": {
+                    "type": "string",
+                    "example": "application/zip"
+                },
                 "fileName": {
+                    "type": "string",
+                    "example": "my-api.zip"
+                },
+                "sha256": {
                     "type": "string"
                 },
-                "message": {
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "Download URL",
+                    "type": "string",
+                    "example": "/api/jobs/4f1c.../artifact"
+                }
+            }
+        },
+        "types.JobProgress": {
+            "type": "object",
+            "properties": {
+                "phase": {
+                    "description": "Current phase, or the last one once the job finished",
+                    "type": "string",
+                    "example": "handlers"
+                },
+                "step": {
+                    "description": "Completed steps",
+                    "type": "integer"
+                },
+                "steps": {
+                    "description": "Total steps",
+                    "type": "integer"
+                }
+            }
+        },
+        "types.JobResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/types.Job"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "types.LibrariesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Library"
+                    }
+                },
+                "sources": {
+                    "description": "Sources of all libraries, also when filtered by source",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "types.Library": {
+            "type": "object",
+            "properties": {
+                "apiURL": {
+                    "description": "Base URL of a self-hosted tags API",
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "conflicts": {
+                    "description": "Libraries that cannot be selected with this one",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "databases": {
+                    "description": "Database types the library works with, any when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "implies": {
+                    "description": "Libraries added with this one when addImplied is set",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "latestPrerelease": {
+                    "description": "Highest pre-release tag newer than LatestStable",
+                    "type": "string"
+                },
+                "latestStable": {
+                    "description": "Highest stable tag of the repository",
+                    "type": "string"
+                },
+                "modulePath": {
+                    "description": "Go module path used in go.mod and imports",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Catalog name, prefixed with \"source/\" outside the built-in catalog",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Tags API of the repository host: \"github\", \"gitlab\" or \"gitea\"",
+                    "type": "string"
+                },
+                "repoURL": {
+                    "type": "string"
+                },
+                "requires": {
+                    "description": "Libraries that must be selected with this one",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "requiresDB": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "description": "Catalog the library comes from (\"builtin\" or an organization)",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version used in generated go.mod files",
+                    "type": "string"
+                }
+            }
+        },
+        "types.PreviewFile": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Set with includeContents",
+                    "type": "string"
+                },
+                "dir": {
+                    "type": "boolean"
+                },
+                "path": {
+                    "description": "Slash-separated path relative to the project root",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "types.PreviewRequest": {
+            "type": "object",
+            "properties": {
+                "addImplied": {
+                    "description": "Add the libraries implied by the selected ones",
+                    "type": "boolean"
+                },
+                "database": {
+                    "$ref": "#/definitions/types.DatabaseConfig"
+                },
+                "deployment": {
+                    "description": "\"railway\", \"local\", \"docker\"",
                     "type": "string"
                 },
+                "format": {
+                    "description": "Archive format: \"zip\", \"tar.gz\" or \"tar.zst\"; negotiated from Accept when empty",
+                    "type": "string"
+                },
+                "includeContents": {
+                    "description": "Return the content of every file, not only its path and size",
+                    "type": "boolean"
+                },
+                "libraries": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "modulePath": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prerelease": {
+                    "description": "Use pre-release library versions newer than the latest stable",
+                    "type": "boolean"
+                },
+                "resolveDependencies": {
+                    "description": "Tidy go.mod and write go.sum through the module proxy",
+                    "type": "boolean"
+                },
+                "structure": {
+                    "description": "\"simple\" or \"standard\"",
+                    "type": "string"
+                },
+                "verify": {
+                    "description": "Type-check the generated code before packaging",
+                    "type": "boolean"
+                },
+                "versions": {
+                    "description": "Per-library version overrides (e.g. {\"go-auth\": \"v1.2.0\"})",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "types.PreviewResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Number of files",
+                    "type": "integer"
+                },
+                "files": {
+                    "description": "Files and directories of the project, sorted by path",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.PreviewFile"
+                    }
+                },
+                "size": {
+                    "description": "Total size of the files in bytes",
+                    "type": "integer"
+                },
                 "success": {
                     "type": "boolean"
                 }
@@ -0,0 +1,8 @@
+// Package auth is a verification stub of github.com/OkanUysal/go-auth.
+package auth
+
+type Claims struct {
+	UserID string
+}
+
+func ValidateToken(token string) (*Claims, error) { return &Claims{}, nil }
@@ -0,0 +1,57 @@
+// Package logger is a verification stub of github.com/OkanUysal/go-logger v1.0.1.
+package logger
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatText
+)
+
+type Config struct {
+	Level      Level
+	Format     Format
+	ShowCaller bool
+	ShowColors bool
+	TimeFormat string
+}
+
+func DefaultConfig() *Config                       { return &Config{} }
+func (c *Config) WithLevel(level Level) *Config    { return c }
+func (c *Config) WithFormat(format Format) *Config { return c }
+
+type Field struct{}
+
+func String(key, value string) Field    { return Field{} }
+func Int(key string, value int) Field   { return Field{} }
+func Bool(key string, value bool) Field { return Field{} }
+func Err(err error) Field               { return Field{} }
+func Any(key string, value any) Field   { return Field{} }
+
+type Logger struct{}
+
+func New(config *Config) *Logger                    { return &Logger{} }
+func Default() *Logger                              { return &Logger{} }
+func SetDefault(logger *Logger)                     {}
+func (l *Logger) With(fields ...Field) *Logger      { return l }
+func (l *Logger) Debug(msg string, fields ...Field) {}
+func (l *Logger) Info(msg string, fields ...Field)  {}
+func (l *Logger) Warn(msg string, fields ...Field)  {}
+func (l *Logger) Error(msg string, fields ...Field) {}
+func (l *Logger) Fatal(msg string, fields ...Field) {}
+
+func Debug(msg string, fields ...Field) {}
+func Info(msg string, fields ...Field)  {}
+func Warn(msg string, fields ...Field)  {}
+func Error(msg string, fields ...Field) {}
+func Fatal(msg string, fields ...Field) {}
@@ -0,0 +1,26 @@
+// Package metrics is a verification stub of github.com/OkanUysal/go-metrics v1.3.0.
+package metrics
+
+import "github.com/gin-gonic/gin"
+
+type Config struct {
+	ServiceName           string
+	Namespace             string
+	Subsystem             string
+	EnableHTTPMetrics     bool
+	EnableMetricsEndpoint bool
+	EnableHealthEndpoint  bool
+}
+
+type MetricLabels map[string]string
+
+type Metrics struct{}
+
+func DefaultConfig() *Config                                         { return &Config{} }
+func NewMetrics(config *Config) *Metrics                             { return &Metrics{} }
+func (m *Metrics) Setup(router *gin.Engine)                          {}
+func (m *Metrics) GinMiddleware() gin.HandlerFunc                    { return nil }
+func (m *Metrics) Middleware() gin.HandlerFunc                       { return nil }
+func (m *Metrics) MetricsEndpoint() gin.HandlerFunc                  { return nil }
+func (m *Metrics) HealthEndpoint() gin.HandlerFunc                   { return nil }
+func (m *Metrics) IncrementCounter(name string, labels MetricLabels) {}
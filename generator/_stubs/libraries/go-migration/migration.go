@@ -0,0 +1,5 @@
+// Package migration is a verification stub of github.com/OkanUysal/go-migration.
+package migration
+
+func Up(databaseURL, dir string) error   { return nil }
+func Down(databaseURL, dir string) error { return nil }
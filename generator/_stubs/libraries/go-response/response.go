@@ -0,0 +1,7 @@
+// Package response is a verification stub of github.com/OkanUysal/go-response.
+package response
+
+import "github.com/gin-gonic/gin"
+
+func Success(c *gin.Context, data any)               {}
+func Error(c *gin.Context, code int, message string) {}
@@ -0,0 +1,45 @@
+// Package gin is a verification stub of github.com/gin-gonic/gin covering the API used by generated projects.
+package gin
+
+type H map[string]any
+
+type HandlerFunc func(*Context)
+
+type Context struct{}
+
+func (c *Context) JSON(code int, obj any)                  {}
+func (c *Context) GetHeader(key string) string             { return "" }
+func (c *Context) Param(key string) string                 { return "" }
+func (c *Context) Query(key string) string                 { return "" }
+func (c *Context) ShouldBindJSON(obj any) error            { return nil }
+func (c *Context) Set(key string, value any)               {}
+func (c *Context) Get(key string) (value any, exists bool) { return nil, false }
+func (c *Context) Abort()                                  {}
+func (c *Context) AbortWithStatus(code int)                {}
+func (c *Context) Next()                                   {}
+
+type IRoutes interface {
+	Use(...HandlerFunc) IRoutes
+	GET(string, ...HandlerFunc) IRoutes
+	POST(string, ...HandlerFunc) IRoutes
+	PUT(string, ...HandlerFunc) IRoutes
+	DELETE(string, ...HandlerFunc) IRoutes
+}
+
+type RouterGroup struct{}
+
+func (g *RouterGroup) Use(middleware ...HandlerFunc) IRoutes                   { return g }
+func (g *RouterGroup) GET(path string, handlers ...HandlerFunc) IRoutes        { return g }
+func (g *RouterGroup) POST(path string, handlers ...HandlerFunc) IRoutes       { return g }
+func (g *RouterGroup) PUT(path string, handlers ...HandlerFunc) IRoutes        { return g }
+func (g *RouterGroup) DELETE(path string, handlers ...HandlerFunc) IRoutes     { return g }
+func (g *RouterGroup) Group(path string, handlers ...HandlerFunc) *RouterGroup { return g }
+
+type Engine struct {
+	RouterGroup
+}
+
+func New() *Engine                                      { return &Engine{} }
+func Default() *Engine                                  { return &Engine{} }
+func (e *Engine) Use(middleware ...HandlerFunc) IRoutes { return e }
+func (e *Engine) Run(addr ...string) error              { return nil }
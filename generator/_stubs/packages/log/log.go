@@ -0,0 +1,14 @@
+// Package log is a verification stub of the standard library log package.
+package log
+
+func Print(v ...any)                 {}
+func Printf(format string, v ...any) {}
+func Println(v ...any)               {}
+
+func Fatal(v ...any)                 {}
+func Fatalf(format string, v ...any) {}
+func Fatalln(v ...any)               {}
+
+func Panic(v ...any)                 {}
+func Panicf(format string, v ...any) {}
+func Panicln(v ...any)               {}
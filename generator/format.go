@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// formatGoSource parses a generated Go file and returns it gofmt'ed.
+// Parse errors are reported as path:line:column so broken templates are easy to locate.
+func formatGoSource(path, content string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.ToSlash(path), content, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("generated Go file does not parse: %w", err)
+	}
+
+	ast.SortImports(fset, file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return buf.String(), nil
+}
+
+// isGoFile checks if a generated path is a Go source file
+func isGoFile(path string) bool {
+	return strings.HasSuffix(path, ".go")
+}
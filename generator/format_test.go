@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFormatGoSource(t *testing.T) {
+	got, err := formatGoSource("main.go", "package main\nimport (\n\"os\"\n\"fmt\"\n)\nfunc main(){fmt.Println(os.Args)}\n")
+	if err != nil {
+		t.Fatalf("formatGoSource: %v", err)
+	}
+	want := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	if got != want {
+		t.Errorf("formatGoSource = %q, want %q", got, want)
+	}
+}
+
+func TestFormatGoSourceError(t *testing.T) {
+	tests := []struct {
+		path, src, want string
+	}{
+		{"main.go", "package main\n\nfunc main() {\n\tx := \n}\n", "main.go:5:1: expected operand"},
+		{"handlers/health.go", "package handlers\n\nfunc Health( {\n}\n", "handlers/health.go:3:"},
+	}
+	for _, tt := range tests {
+		_, err := formatGoSource(tt.path, tt.src)
+		if err == nil || !strings.Contains(err.Error(), "generated Go file does not parse: "+tt.want) {
+			t.Errorf("formatGoSource(%s) error = %v, want %q", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestWriteTemplateParseError(t *testing.T) {
+	defer func(t *template.Template) { templates = t }(templates)
+	templates = template.Must(template.Must(templates.Clone()).New("broken.go.tmpl").Parse(
+		"package main\n\nfunc main() {\n\tprintln({{printf \"%q\" .Name}}\n}\n",
+	))
+
+	config := &ProjectConfig{Name: "demo-api", ModulePath: "github.com/example/demo-api"}
+	err := writeTemplate(config, NewMemOutput(), "main.go", "broken.go.tmpl")
+	if err == nil || !strings.Contains(err.Error(), "main.go:4:20: missing ','") {
+		t.Errorf("writeTemplate error = %v, want a main.go:4:20 parse error", err)
+	}
+}
@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+const goldenSuffix = ".golden"
+
+// goldenLibraries are the library subsets of the golden matrix
+var goldenLibraries = map[string][]string{
+	"nolibs": nil,
+	"core":   {"go-logger", "go-response", "go-metrics"},
+	"all": {
+		"go-auth", "go-migration", "go-logger", "go-cache", "go-swagger",
+		"go-response", "go-validator", "go-pagination", "go-websocket", "go-metrics",
+	},
+}
+
+// goldenCases returns the Structure × Database × Deployment × libraries matrix
+func goldenCases() map[string]ProjectConfig {
+	cases := make(map[string]ProjectConfig)
+	for _, structure := range []string{"simple", "standard"} {
+		for _, database := range []string{"none", "postgres", "mysql", "mongodb"} {
+			for _, deployment := range []string{"railway", "docker"} {
+				for libs, libraries := range goldenLibraries {
+					name := fmt.Sprintf("%s-%s-%s-%s", structure, database, deployment, libs)
+					cases[name] = ProjectConfig{
+						Name:       "demo-api",
+						ModulePath: "github.com/example/demo-api",
+						Structure:  structure,
+						Database:   database,
+						Libraries:  libraries,
+						Deployment: deployment,
+					}
+				}
+			}
+		}
+	}
+	return cases
+}
+
+func TestGenerateProjectGolden(t *testing.T) {
+	for name, config := range goldenCases() {
+		t.Run(name, func(t *testing.T) {
+			project := NewMemOutput()
+			config.Output = project
+			config.Verify = true
+
+			if err := GenerateProject(context.Background(), &config); err != nil {
+				t.Fatalf("GenerateProject: %v", err)
+			}
+
+			got := readTree(t, project, "")
+			goldenDir := filepath.Join("testdata", "golden", name)
+
+			if *update {
+				writeGolden(t, goldenDir, got)
+				return
+			}
+
+			want := readTree(t, os.DirFS(goldenDir), goldenSuffix)
+			compareTrees(t, want, got)
+		})
+	}
+}
+
+func TestGenerateProjectLibraryPaths(t *testing.T) {
+	config := ProjectConfig{
+		Name:       "demo-api",
+		ModulePath: "github.com/example/demo-api",
+		Libraries:  []string{"go-logger", "go-response", "acme/go-queue"},
+		Versions:   map[string]string{"go-logger": "v2.1.0", "acme/go-queue": "v0.3.0"},
+		Paths: map[string]string{
+			"go-logger":     "gitlab.com/acme/go-logger",
+			"acme/go-queue": "go.acme.dev/queue",
+		},
+		Verify:    true, // go-logger is checked against its stub at the configured path
+		OutputDir: t.TempDir(),
+	}
+	if err := GenerateProject(context.Background(), &config); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+	files := readTree(t, os.DirFS(config.OutputDir), "")
+
+	for _, want := range []string{
+		"\tgitlab.com/acme/go-logger v2.1.0\n",
+		"\tgithub.com/OkanUysal/go-response v1.0.0\n",
+		"\tgo.acme.dev/queue v0.3.0\n",
+	} {
+		if !strings.Contains(files["go.mod"], want) {
+			t.Errorf("go.mod does not require %q:\n%s", strings.TrimSpace(want), files["go.mod"])
+		}
+	}
+	if !strings.Contains(files["main.go"], `"gitlab.com/acme/go-logger"`) {
+		t.Errorf("main.go does not import the configured go-logger path:\n%s", files["main.go"])
+	}
+}
+
+// readTree returns the files of fsys keyed by path, with suffix trimmed
+func readTree(t *testing.T, fsys fs.FS, suffix string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+
+		files[strings.TrimSuffix(path, suffix)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read project: %v (run go test ./generator -update to create golden files)", err)
+	}
+	return files
+}
+
+// writeGolden replaces the golden directory with the generated files
+func writeGolden(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel)+goldenSuffix)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// compareTrees reports missing, unexpected and changed files
+func compareTrees(t *testing.T, want, got map[string]string) {
+	t.Helper()
+
+	paths := make(map[string]bool)
+	for p := range want {
+		paths[p] = true
+	}
+	for p := range got {
+		paths[p] = true
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, p := range sorted {
+		wantContent, inWant := want[p]
+		gotContent, inGot := got[p]
+
+		switch {
+		case !inGot:
+			t.Errorf("%s: missing from generated project", p)
+		case !inWant:
+			t.Errorf("%s: not in golden files", p)
+		case wantContent != gotContent:
+			t.Errorf("%s: content differs from golden file\n%s", p, firstDiff(wantContent, gotContent))
+		}
+	}
+}
+
+// firstDiff describes the first differing line of two files
+func firstDiff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n  want: %q\n  got:  %q", i+1, w, g)
+		}
+	}
+	return ""
+}
+
+func TestGenerateProjectProgress(t *testing.T) {
+	tests := []struct {
+		deployment string
+		verify     bool
+		phases     []string
+	}{
+		{"railway", false, []string{
+			PhaseStructure, PhaseGoMod, PhaseMain, PhaseConfig, PhaseHandlers, PhaseLibraries,
+			PhaseEnv, PhaseGitignore, PhaseRailway, PhaseReadme, PhaseWrite,
+		}},
+		{"docker", true, []string{
+			PhaseStructure, PhaseGoMod, PhaseMain, PhaseConfig, PhaseHandlers, PhaseLibraries,
+			PhaseEnv, PhaseGitignore, PhaseReadme, PhaseVerify, PhaseWrite,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.deployment, func(t *testing.T) {
+			var phases []string
+			config := ProjectConfig{
+				Name:       "demo-api",
+				ModulePath: "github.com/example/demo-api",
+				Deployment: tt.deployment,
+				Verify:     tt.verify,
+				Output:     NewMemOutput(),
+				Progress: func(event ProgressEvent) {
+					if event.Step != len(phases) || event.Steps != len(tt.phases) {
+						t.Errorf("%s is step %d of %d, want %d of %d", event.Phase, event.Step, event.Steps, len(phases), len(tt.phases))
+					}
+					phases = append(phases, event.Phase)
+				},
+			}
+			if err := GenerateProject(context.Background(), &config); err != nil {
+				t.Fatalf("GenerateProject: %v", err)
+			}
+			if strings.Join(phases, " ") != strings.Join(tt.phases, " ") {
+				t.Errorf("phases = %v, want %v", phases, tt.phases)
+			}
+		})
+	}
+}
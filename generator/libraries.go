@@ -0,0 +1,111 @@
+package generator
+
+// go-logger: structured logging configured from LOG_LEVEL
+type loggerPlugin struct{ basePlugin }
+
+func (p loggerPlugin) Imports(data *TemplateData) []string {
+	return []string{data.LibraryPath(p.name)}
+}
+
+func (loggerPlugin) MainInit(*TemplateData) string {
+	return `	logConfig := logger.DefaultConfig()
+	switch cfg.LogLevel {
+	case "debug":
+		logConfig.Level = logger.LevelDebug
+	case "warn":
+		logConfig.Level = logger.LevelWarn
+	case "error":
+		logConfig.Level = logger.LevelError
+	}
+	logger.SetDefault(logger.New(logConfig))
+`
+}
+
+func (loggerPlugin) ConfigFields(*TemplateData) []ConfigField {
+	return []ConfigField{{Name: "LogLevel", Env: "LOG_LEVEL", Default: "info"}}
+}
+
+func (loggerPlugin) EnvVars(*TemplateData) []EnvVar {
+	return []EnvVar{{Name: "LOG_LEVEL", Value: "info"}}
+}
+
+// go-auth: JWT secret and an auth middleware
+type authPlugin struct{ basePlugin }
+
+func (authPlugin) ConfigFields(*TemplateData) []ConfigField {
+	return []ConfigField{{Name: "JWTSecret", Env: "JWT_SECRET", Default: ""}}
+}
+
+func (authPlugin) EnvVars(*TemplateData) []EnvVar {
+	return []EnvVar{{Name: "JWT_SECRET", Value: "your-secret-key"}}
+}
+
+func (authPlugin) Files(data *TemplateData) []PluginFile {
+	path := "middleware.go"
+	if data.Standard {
+		path = "internal/middleware/auth.go"
+	}
+	return []PluginFile{{Path: path, Template: "middleware.go.tmpl"}}
+}
+
+// go-migration: runs migrations on startup when a database is configured
+type migrationPlugin struct{ basePlugin }
+
+func (p migrationPlugin) Imports(data *TemplateData) []string {
+	if !data.HasDatabase {
+		return nil
+	}
+	return []string{data.LibraryPath(p.name)}
+}
+
+func (migrationPlugin) MainInit(data *TemplateData) string {
+	if !data.HasDatabase {
+		return ""
+	}
+	return `	if err := migration.Up(cfg.DatabaseURL, "./migrations"); err != nil {
+		log.Fatal(err)
+	}
+`
+}
+
+func (migrationPlugin) Directories(*TemplateData) []string {
+	return []string{"migrations"}
+}
+
+// go-cache: dependency only
+type cachePlugin struct{ basePlugin }
+
+// go-swagger: dependency only, UI setup needs swag-generated docs
+type swaggerPlugin struct{ basePlugin }
+
+// go-response: used by the generated handlers
+type responsePlugin struct{ basePlugin }
+
+// go-validator: dependency only
+type validatorPlugin struct{ basePlugin }
+
+// go-pagination: dependency only
+type paginationPlugin struct{ basePlugin }
+
+// go-websocket: dependency only
+type websocketPlugin struct{ basePlugin }
+
+// go-metrics: Prometheus middleware and /metrics endpoint
+type metricsPlugin struct{ basePlugin }
+
+func (p metricsPlugin) Imports(data *TemplateData) []string {
+	return []string{data.LibraryPath(p.name)}
+}
+
+func (metricsPlugin) MainInit(*TemplateData) string {
+	return `	metricsCollector := metrics.NewMetrics(&metrics.Config{
+		ServiceName: cfg.AppName,
+	})
+`
+}
+
+func (metricsPlugin) MainRoutes(*TemplateData) string {
+	return `	router.Use(metricsCollector.Middleware())
+	router.GET("/metrics", metricsCollector.MetricsEndpoint())
+`
+}
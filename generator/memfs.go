@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// memFS is a read-only file system of files and directories kept in a map by slash-separated path.
+// Directories are entries with fs.ModeDir; the root "." always exists.
+type memFS map[string]*memFile
+
+// memFile is a file or directory of a memFS
+type memFile struct {
+	Data []byte
+	Mode fs.FileMode
+}
+
+// Open implements fs.FS
+func (m memFS) Open(name string) (fs.File, error) {
+	info, err := m.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		entries, err := m.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return &openMemDir{path: name, info: info, entries: entries}, nil
+	}
+	return &openMemFile{Reader: bytes.NewReader(m[name].Data), info: info}, nil
+}
+
+// ReadDir implements fs.ReadDirFS, with entries sorted by name
+func (m memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := m.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	var entries []fs.DirEntry
+	for p, file := range m {
+		if p != name && path.Dir(p) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: path.Base(p), size: int64(len(file.Data)), mode: file.Mode}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// ReadFile implements fs.ReadFileFS
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	info, err := m.stat("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	return bytes.Clone(m[name].Data), nil
+}
+
+// stat returns the info of a path, or a PathError for op
+func (m memFS) stat(op, name string) (memInfo, error) {
+	if !fs.ValidPath(name) {
+		return memInfo{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return memInfo{name: ".", mode: fs.ModeDir | 0755}, nil
+	}
+	file, ok := m[name]
+	if !ok {
+		return memInfo{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return memInfo{name: path.Base(name), size: int64(len(file.Data)), mode: file.Mode}, nil
+}
+
+// memInfo implements fs.FileInfo for memFS entries
+type memInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
+
+// openMemFile is an open memFS file
+type openMemFile struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openMemFile) Close() error               { return nil }
+
+// openMemDir is an open memFS directory
+type openMemDir struct {
+	path    string
+	info    memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openMemDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openMemDir) Close() error               { return nil }
+
+func (d *openMemDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *openMemDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.entries[d.offset:]
+	if n > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		entries = entries[:min(n, len(entries))]
+	}
+	d.offset += len(entries)
+	return entries, nil
+}
@@ -0,0 +1,696 @@
+package generator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"go/version"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+const (
+	// fetchConcurrency limits parallel requests to the module proxy
+	fetchConcurrency = 8
+	// maxTidyRounds bounds the fixpoint iteration of Tidy
+	maxTidyRounds = 10
+)
+
+// fetchLimits bounds the size of the module files downloaded from a proxy, by suffix
+var fetchLimits = map[string]int64{".mod": modzip.MaxGoMod, ".zip": modzip.MaxZipFile}
+
+// ModuleProxy resolves module requirements and checksums through the GOPROXY protocol.
+// URL is the base URL of an http(s) proxy or a file:// URL of a directory laid out like one.
+type ModuleProxy struct {
+	URL    string
+	Client *http.Client
+}
+
+// DefaultModuleProxy returns the first proxy listed in GOPROXY, or proxy.golang.org
+func DefaultModuleProxy() string {
+	for _, entry := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if entry != "direct" && entry != "off" {
+			return entry
+		}
+	}
+	return "https://proxy.golang.org"
+}
+
+// Tidy does what "go mod tidy" does for a module whose Go files import the given packages.
+// Versions are chosen by minimal version selection over the pruned module graph of Go 1.17+,
+// go.mod keeps every module providing a package in the import graph (the ones not imported
+// by the project itself marked indirect) and go.sum lists the checksums needed to build it.
+func (p *ModuleProxy) Tidy(ctx context.Context, gomod []byte, imports []string) (mod, sum []byte, err error) {
+	file, err := modfile.Parse("go.mod", gomod, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t := &tidier{
+		proxy: p,
+		nodes: make(map[module.Version]*modNode),
+		zips:  make(map[module.Version]*modZip),
+	}
+
+	var roots []module.Version
+	for _, r := range file.Require {
+		roots = append(roots, r.Mod)
+	}
+
+	// The providing modules become the requirements of go.mod, which changes the graph;
+	// repeat until they are stable
+	var (
+		loaded   map[module.Version]bool
+		selected map[string]string
+		pkgs     *packageSet
+	)
+	for round := 0; ; round++ {
+		if round == maxTidyRounds {
+			return nil, nil, fmt.Errorf("module requirements did not settle after %d rounds", maxTidyRounds)
+		}
+
+		var vertices map[module.Version]bool
+		if vertices, loaded, err = t.loadGraph(ctx, roots); err != nil {
+			return nil, nil, err
+		}
+
+		selected = make(map[string]string)
+		for m := range vertices {
+			if semver.Compare(m.Version, selected[m.Path]) > 0 {
+				selected[m.Path] = m.Version
+			}
+		}
+
+		if pkgs, err = t.loadPackages(ctx, imports, selected); err != nil {
+			return nil, nil, err
+		}
+
+		// A package missing from the graph may be provided once the module importing it
+		// becomes a requirement and its own requirements join the graph
+		if sameRequirements(roots, pkgs.providers, selected) {
+			if len(pkgs.missing) > 0 {
+				return nil, nil, fmt.Errorf("no required module provides package %s", pkgs.missing[0])
+			}
+			break
+		}
+
+		roots = roots[:0]
+		for modPath := range pkgs.providers {
+			roots = append(roots, module.Version{Path: modPath, Version: selected[modPath]})
+		}
+	}
+
+	goVersion := "go" + file.Go.Version
+	reqs := make([]*modfile.Require, 0, len(roots))
+	for _, m := range roots {
+		if dep := t.nodes[m].file; dep.Go != nil && version.Compare("go"+dep.Go.Version, goVersion) > 0 {
+			goVersion = "go" + dep.Go.Version
+		}
+		reqs = append(reqs, &modfile.Require{Mod: m, Indirect: !pkgs.direct[m.Path]})
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Mod.Path < reqs[j].Mod.Path })
+
+	if err := file.AddGoStmt(strings.TrimPrefix(goVersion, "go")); err != nil {
+		return nil, nil, err
+	}
+	file.SetRequireSeparateIndirect(reqs)
+	file.SortBlocks()
+	file.Cleanup()
+
+	if mod, err = file.Format(); err != nil {
+		return nil, nil, err
+	}
+
+	// Modules providing packages only imported by tests are in the graph without being loaded
+	var unloaded []module.Version
+	for m := range pkgs.used {
+		if _, ok := loaded[m]; !ok {
+			loaded[m] = false
+			if t.nodes[m] == nil {
+				unloaded = append(unloaded, m)
+			}
+		}
+	}
+	if err := t.loadNodes(ctx, unloaded); err != nil {
+		return nil, nil, err
+	}
+
+	return mod, t.goSum(loaded, pkgs.checked), nil
+}
+
+// tidier holds the go.mod files and module zips downloaded during one Tidy call
+type tidier struct {
+	proxy *ModuleProxy
+	nodes map[module.Version]*modNode
+	zips  map[module.Version]*modZip
+}
+
+// modNode is a module version whose go.mod has been read
+type modNode struct {
+	file    *modfile.File
+	modHash string // go.sum hash of the go.mod file
+}
+
+// pruned checks if the module supports graph pruning (go 1.17 or later)
+func (n *modNode) pruned() bool {
+	return n.file.Go != nil && version.Compare("go"+n.file.Go.Version, "go1.17") >= 0
+}
+
+// modZip is a downloaded module zip indexed by package directory
+type modZip struct {
+	hash string                 // go.sum hash of the zip
+	dirs map[string][]*zip.File // Go files keyed by directory relative to the module root
+}
+
+// packageSet is the result of loading the packages imported by a module
+type packageSet struct {
+	providers map[string]bool         // module paths providing at least one package
+	direct    map[string]bool         // module paths providing a package imported by the module itself
+	used      map[module.Version]bool // modules providing any loaded package; their go.mod checksums are kept
+	checked   map[module.Version]bool // modules searched for a package; their zip checksums are kept
+	missing   []string                // packages no module in the graph provides
+}
+
+// loadGraph walks the pruned module graph rooted at roots.
+// The requirements of every root are part of the graph, but they are only followed further below
+// modules that predate graph pruning. It returns the module versions in the graph and the ones
+// whose go.mod was read.
+func (t *tidier) loadGraph(ctx context.Context, roots []module.Version) (vertices, loaded map[module.Version]bool, err error) {
+	type item struct {
+		mod      module.Version
+		unpruned bool // reached through a module without graph pruning
+	}
+
+	vertices = make(map[module.Version]bool)
+	loaded = make(map[module.Version]bool) // value: requirements were followed as unpruned
+
+	level := make([]item, 0, len(roots))
+	for _, m := range roots {
+		level = append(level, item{mod: m})
+	}
+
+	for len(level) > 0 {
+		var current []item
+		var missing []module.Version
+		for _, it := range level {
+			unpruned, seen := loaded[it.mod]
+			if seen && (unpruned || !it.unpruned) {
+				continue
+			}
+			if !seen && t.nodes[it.mod] == nil {
+				missing = append(missing, it.mod)
+			}
+			loaded[it.mod] = it.unpruned
+			vertices[it.mod] = true
+			current = append(current, it)
+		}
+
+		if err := t.loadNodes(ctx, missing); err != nil {
+			return nil, nil, err
+		}
+
+		level = nil
+		for _, it := range current {
+			node := t.nodes[it.mod]
+			follow := it.unpruned || !node.pruned()
+			for _, r := range node.file.Require {
+				vertices[r.Mod] = true
+				if follow {
+					level = append(level, item{mod: r.Mod, unpruned: true})
+				}
+			}
+		}
+	}
+
+	return vertices, loaded, nil
+}
+
+// loadNodes fetches and parses the go.mod of each module in parallel
+func (t *tidier) loadNodes(ctx context.Context, mods []module.Version) error {
+	result := make([]*modNode, len(mods))
+	err := forEach(len(mods), func(i int) error {
+		m := mods[i]
+		data, err := t.proxy.fetch(ctx, m, ".mod")
+		if err != nil {
+			return err
+		}
+
+		node := &modNode{}
+		if node.file, err = modfile.ParseLax(m.Path+"@"+m.Version+"/go.mod", data, nil); err != nil {
+			return err
+		}
+		if node.modHash, err = hashGoMod(data); err != nil {
+			return err
+		}
+		result[i] = node
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, m := range mods {
+		t.nodes[m] = result[i]
+	}
+	return nil
+}
+
+// loadPackages finds the module providing each imported package and follows the imports of
+// those packages and of their tests, one level of the import graph at a time.
+// Like "go mod tidy", packages only imported by tests of dependencies are outside the "all"
+// pattern: they are resolved for go.sum, but their tests are not loaded and their modules do
+// not become requirements.
+func (t *tidier) loadPackages(ctx context.Context, imports []string, selected map[string]string) (*packageSet, error) {
+	pkgs := &packageSet{
+		providers: make(map[string]bool),
+		direct:    make(map[string]bool),
+		used:      make(map[module.Version]bool),
+		checked:   make(map[module.Version]bool),
+	}
+
+	type item struct {
+		pkg string
+		all bool // the package matches "all"
+	}
+
+	direct := make(map[string]bool)
+	seen := make(map[string]bool) // value: loaded as part of "all"
+	var level []item
+	for _, imp := range imports {
+		if !isStdPackage(imp) && !direct[imp] {
+			direct[imp] = true
+			seen[imp] = true
+			level = append(level, item{pkg: imp, all: true})
+		}
+	}
+
+	for len(level) > 0 {
+		// Download every module that may provide a package of this level
+		var missing []module.Version
+		queued := make(map[module.Version]bool)
+		for _, it := range level {
+			for _, m := range candidateModules(it.pkg, selected) {
+				if t.zips[m] == nil && !queued[m] {
+					queued[m] = true
+					missing = append(missing, m)
+				}
+			}
+		}
+		if err := t.loadZips(ctx, missing); err != nil {
+			return nil, err
+		}
+
+		var next []item
+		for _, it := range level {
+			var (
+				provider module.Version
+				files    []*zip.File
+			)
+			for _, m := range candidateModules(it.pkg, selected) {
+				pkgs.checked[m] = true
+				dir := strings.TrimPrefix(strings.TrimPrefix(it.pkg, m.Path), "/")
+				if dir == "" {
+					dir = "."
+				}
+				found := t.zips[m].dirs[dir]
+				if len(found) == 0 {
+					continue
+				}
+				if files != nil {
+					return nil, fmt.Errorf("ambiguous import: package %s is provided by %s and %s", it.pkg, provider.Path, m.Path)
+				}
+				provider, files = m, found
+			}
+			if files == nil {
+				pkgs.missing = append(pkgs.missing, it.pkg)
+				continue
+			}
+
+			deps, testDeps, err := zipImports(files)
+			if err != nil {
+				return nil, fmt.Errorf("package %s: %w", it.pkg, err)
+			}
+
+			pkgs.used[provider] = true
+
+			if it.all {
+				pkgs.providers[provider.Path] = true
+				if direct[it.pkg] {
+					pkgs.direct[provider.Path] = true
+				}
+			} else {
+				testDeps = nil
+			}
+
+			for _, dep := range deps {
+				if all, ok := seen[dep]; !isStdPackage(dep) && (!ok || it.all && !all) {
+					seen[dep] = it.all
+					next = append(next, item{pkg: dep, all: it.all})
+				}
+			}
+			for _, dep := range testDeps {
+				if _, ok := seen[dep]; !isStdPackage(dep) && !ok {
+					seen[dep] = false
+					next = append(next, item{pkg: dep})
+				}
+			}
+		}
+		level = next
+	}
+
+	return pkgs, nil
+}
+
+// loadZips downloads, hashes and indexes module zips in parallel
+func (t *tidier) loadZips(ctx context.Context, mods []module.Version) error {
+	result := make([]*modZip, len(mods))
+	err := forEach(len(mods), func(i int) error {
+		m := mods[i]
+		data, err := t.proxy.fetch(ctx, m, ".zip")
+		if err != nil {
+			return err
+		}
+
+		if result[i], err = indexZip(m, data); err != nil {
+			return fmt.Errorf("%s@%s: %w", m.Path, m.Version, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, m := range mods {
+		t.zips[m] = result[i]
+	}
+	return nil
+}
+
+// goSum lists the go.mod hash of every given module and the zip hash of every searched module
+func (t *tidier) goSum(loaded, checked map[module.Version]bool) []byte {
+	lines := make(map[module.Version]string)
+	for m := range loaded {
+		lines[module.Version{Path: m.Path, Version: m.Version + "/go.mod"}] = t.nodes[m].modHash
+	}
+	for m := range checked {
+		lines[m] = t.zips[m].hash
+	}
+
+	keys := make([]module.Version, 0, len(lines))
+	for k := range lines {
+		keys = append(keys, k)
+	}
+	module.Sort(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s %s %s\n", k.Path, k.Version, lines[k])
+	}
+	return buf.Bytes()
+}
+
+// fetch downloads a module file from the proxy; suffix is ".mod" or ".zip"
+func (p *ModuleProxy) fetch(ctx context.Context, m module.Version, suffix string) ([]byte, error) {
+	escPath, err := module.EscapePath(m.Path)
+	if err != nil {
+		return nil, err
+	}
+	escVersion, err := module.EscapeVersion(m.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	rel := escPath + "/@v/" + escVersion + suffix
+	base := strings.TrimSuffix(p.URL, "/")
+
+	if dir, ok := strings.CutPrefix(base, "file://"); ok {
+		return os.ReadFile(filepath.Join(filepath.FromSlash(dir), filepath.FromSlash(rel)))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/"+rel, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s@%s%s: proxy returned %s", m.Path, m.Version, suffix, resp.Status)
+	}
+
+	// Reading one byte past the limit tells an oversized file from one of exactly the limit,
+	// instead of hashing a truncated file into a wrong go.sum line
+	limit := fetchLimits[suffix]
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s@%s%s: larger than %d bytes", m.Path, m.Version, suffix, limit)
+	}
+	return data, nil
+}
+
+// forEach runs fn for 0..n-1 with bounded concurrency and returns the first error
+func forEach(n int, fn func(i int) error) error {
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		sem      = make(chan struct{}, fetchConcurrency)
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(i); err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// ProjectImports returns the packages imported by the Go files of a project, excluding its own packages
+func ProjectImports(root fs.FS, modulePath string) ([]string, error) {
+	fset := token.NewFileSet()
+	seen := make(map[string]bool)
+
+	err := fs.WalkDir(root, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isGoFile(p) {
+			return err
+		}
+
+		src, err := fs.ReadFile(root, p)
+		if err != nil {
+			return err
+		}
+		file, err := parser.ParseFile(fset, p, src, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+
+		for _, spec := range file.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
+			if imp != modulePath && !strings.HasPrefix(imp, modulePath+"/") {
+				seen[imp] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
+
+// candidateModules returns the selected modules whose path is a prefix of a package path
+func candidateModules(pkg string, selected map[string]string) []module.Version {
+	var mods []module.Version
+	for p := pkg; p != "." && p != "/"; p = path.Dir(p) {
+		if v, ok := selected[p]; ok {
+			mods = append(mods, module.Version{Path: p, Version: v})
+		}
+	}
+	return mods
+}
+
+// sameRequirements checks if roots are exactly the providing modules at their selected versions
+func sameRequirements(roots []module.Version, providers map[string]bool, selected map[string]string) bool {
+	if len(roots) != len(providers) {
+		return false
+	}
+	for _, m := range roots {
+		if !providers[m.Path] || selected[m.Path] != m.Version {
+			return false
+		}
+	}
+	return true
+}
+
+// indexZip hashes a module zip and groups its Go files by directory
+func indexZip(m module.Version, data []byte) (*modZip, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := m.Path + "@" + m.Version + "/"
+	z := &modZip{dirs: make(map[string][]*zip.File)}
+
+	names := make([]string, 0, len(zr.File))
+	index := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		index[f.Name] = f
+
+		rel, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok || !isGoFile(rel) {
+			continue
+		}
+		dir := path.Dir(rel)
+		z.dirs[dir] = append(z.dirs[dir], f)
+	}
+
+	// Same as dirhash.HashZip without a temporary file
+	z.hash, err = dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		return index[name].Open()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// zipImports returns the imports of the Go files of a package from a module zip, split into
+// imports of the package and of its tests. Like "go mod tidy", every build tag except "ignore"
+// counts as both satisfied and unsatisfied.
+func zipImports(files []*zip.File) (imports, testImports []string, err error) {
+	fset := token.NewFileSet()
+
+	for _, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		src, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		file, err := parser.ParseFile(fset, f.Name, src, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !matchAnyTags(file) {
+			continue
+		}
+
+		for _, spec := range file.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch {
+			case imp == "C":
+			case strings.HasSuffix(f.Name, "_test.go"):
+				testImports = append(testImports, imp)
+			default:
+				imports = append(imports, imp)
+			}
+		}
+	}
+
+	return imports, testImports, nil
+}
+
+// matchAnyTags checks if the build constraints above the package clause can be satisfied
+func matchAnyTags(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err == nil && !evalAnyTags(expr, true) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// evalAnyTags evaluates a build constraint where every tag except "ignore" takes the preferred value
+func evalAnyTags(expr constraint.Expr, prefer bool) bool {
+	switch e := expr.(type) {
+	case *constraint.TagExpr:
+		return e.Tag != "ignore" && prefer
+	case *constraint.NotExpr:
+		return !evalAnyTags(e.X, !prefer)
+	case *constraint.AndExpr:
+		return evalAnyTags(e.X, prefer) && evalAnyTags(e.Y, prefer)
+	case *constraint.OrExpr:
+		return evalAnyTags(e.X, prefer) || evalAnyTags(e.Y, prefer)
+	}
+	return false
+}
+
+// hashGoMod returns the go.sum hash of a go.mod file
+func hashGoMod(data []byte) (string, error) {
+	return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+}
+
+// isStdPackage checks if an import path belongs to the standard library (no dot in the first element)
+func isStdPackage(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
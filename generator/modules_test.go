@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"archive/zip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// writeProxyModule adds a module version to a file-based GOPROXY directory.
+// files maps names relative to the module root to their content.
+func writeProxyModule(t *testing.T, dir, path, version, gomod string, files map[string]string) string {
+	t.Helper()
+
+	vdir := filepath.Join(dir, filepath.FromSlash(path), "@v")
+	if err := os.MkdirAll(vdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vdir, version+".mod"), []byte(gomod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	zipPath := filepath.Join(vdir, version+".zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	files["go.mod"] = gomod
+	for name, content := range files {
+		w, err := zw.Create(path + "@" + version + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return zipPath
+}
+
+// goSource returns a Go file of package name importing the given packages
+func goSource(name string, imports ...string) map[string]string {
+	src := "package " + name + "\n"
+	for _, imp := range imports {
+		src += "\nimport _ \"" + imp + "\"\n"
+	}
+	return map[string]string{"lib.go": src}
+}
+
+func TestModuleProxyTidy(t *testing.T) {
+	dir := t.TempDir()
+
+	aFiles := goSource("a", "example.com/b")
+	aFiles["ignored.go"] = "//go:build ignore\n\npackage main\n\nimport _ \"example.com/missing\"\n"
+	aFiles["a_test.go"] = "package a\n\nimport _ \"example.com/t\"\n"
+	tFiles := goSource("t")
+	tFiles["t_test.go"] = "package t\n\nimport _ \"example.com/missing\"\n"
+
+	zipA := writeProxyModule(t, dir, "example.com/a", "v1.0.0", "module example.com/a\n\ngo 1.20\n\nrequire (\n\texample.com/b v1.1.0\n\texample.com/f v1.1.0\n\texample.com/t v1.0.0\n)\n", aFiles)
+	writeProxyModule(t, dir, "example.com/b", "v1.0.0", "module example.com/b\n\ngo 1.20\n", goSource("b"))
+	writeProxyModule(t, dir, "example.com/b", "v1.1.0", "module example.com/b\n\ngo 1.22\n", goSource("b"))
+	writeProxyModule(t, dir, "example.com/c", "v1.0.0", "module example.com/c\n\nrequire example.com/d v1.0.0\n", goSource("c", "example.com/d"))
+	writeProxyModule(t, dir, "example.com/d", "v1.0.0", "module example.com/d\n\ngo 1.20\n\nrequire example.com/b v1.0.0\n", goSource("d"))
+	writeProxyModule(t, dir, "example.com/e", "v1.0.0", "module example.com/e\n\ngo 1.20\n\nrequire example.com/f v1.0.0\n", goSource("e", "example.com/f"))
+	writeProxyModule(t, dir, "example.com/f", "v1.0.0", "module example.com/f\n\ngo 1.20\n\nrequire example.com/x v1.0.0\n", goSource("f"))
+	writeProxyModule(t, dir, "example.com/f", "v1.1.0", "module example.com/f\n\ngo 1.20\n", goSource("f"))
+	writeProxyModule(t, dir, "example.com/g", "v1.0.0", "module example.com/g\n\ngo 1.20\n", goSource("g"))
+	writeProxyModule(t, dir, "example.com/t", "v1.0.0", "module example.com/t\n\ngo 1.20\n", tFiles)
+
+	// c predates graph pruning, so d and its requirement on b v1.0.0 are loaded transitively.
+	// e is pruned and f v1.0.0 is superseded, so example.com/x (absent from the proxy) is never loaded.
+	// g provides no imported package and is dropped. t is only imported by a test of a, so it is
+	// checksummed but not required, and its own tests and the ignored file of a are not followed.
+	gomod := "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/c v1.0.0\n\texample.com/a v1.0.0\n\texample.com/e v1.0.0\n\texample.com/g v1.0.0\n)\n"
+	imports := []string{"example.com/a", "example.com/c", "example.com/e", "fmt"}
+
+	proxy := &ModuleProxy{URL: "file://" + filepath.ToSlash(dir)}
+	mod, sum, err := proxy.Tidy(context.Background(), []byte(gomod), imports)
+	if err != nil {
+		t.Fatalf("Tidy: %v", err)
+	}
+
+	wantMod := `module example.com/app
+
+go 1.22
+
+require (
+	example.com/a v1.0.0
+	example.com/c v1.0.0
+	example.com/e v1.0.0
+)
+
+require (
+	example.com/b v1.1.0 // indirect
+	example.com/d v1.0.0 // indirect
+	example.com/f v1.1.0 // indirect
+)
+`
+	if string(mod) != wantMod {
+		t.Errorf("go.mod:\n%s\nwant:\n%s", mod, wantMod)
+	}
+
+	var keys []string
+	hashes := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(sum)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "h1:") {
+			t.Fatalf("malformed go.sum line %q", line)
+		}
+		key := fields[0] + " " + fields[1]
+		keys = append(keys, key)
+		hashes[key] = fields[2]
+	}
+
+	wantKeys := []string{
+		"example.com/a v1.0.0",
+		"example.com/a v1.0.0/go.mod",
+		"example.com/b v1.0.0/go.mod",
+		"example.com/b v1.1.0",
+		"example.com/b v1.1.0/go.mod",
+		"example.com/c v1.0.0",
+		"example.com/c v1.0.0/go.mod",
+		"example.com/d v1.0.0",
+		"example.com/d v1.0.0/go.mod",
+		"example.com/e v1.0.0",
+		"example.com/e v1.0.0/go.mod",
+		"example.com/f v1.1.0",
+		"example.com/f v1.1.0/go.mod",
+		"example.com/t v1.0.0",
+		"example.com/t v1.0.0/go.mod",
+	}
+	if strings.Join(keys, "\n") != strings.Join(wantKeys, "\n") {
+		t.Errorf("go.sum entries:\n%s\nwant:\n%s", strings.Join(keys, "\n"), strings.Join(wantKeys, "\n"))
+	}
+
+	wantHash, err := dirhash.HashZip(zipA, dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hashes["example.com/a v1.0.0"]; got != wantHash {
+		t.Errorf("zip hash of example.com/a = %s, want %s", got, wantHash)
+	}
+}
+
+func TestModuleProxyTidyMissingModule(t *testing.T) {
+	proxy := &ModuleProxy{URL: "file://" + filepath.ToSlash(t.TempDir())}
+	gomod := "module example.com/app\n\ngo 1.21\n\nrequire example.com/missing v1.0.0\n"
+
+	if _, _, err := proxy.Tidy(context.Background(), []byte(gomod), []string{"example.com/missing"}); err == nil {
+		t.Fatal("expected an error for a module missing from the proxy")
+	}
+}
+
+func TestModuleProxyTidyMissingPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeProxyModule(t, dir, "example.com/a", "v1.0.0", "module example.com/a\n\ngo 1.20\n", goSource("a"))
+
+	proxy := &ModuleProxy{URL: "file://" + filepath.ToSlash(dir)}
+	gomod := "module example.com/app\n\ngo 1.21\n\nrequire example.com/a v1.0.0\n"
+
+	_, _, err := proxy.Tidy(context.Background(), []byte(gomod), []string{"example.com/a/sub"})
+	if err == nil || !strings.Contains(err.Error(), "no required module provides package example.com/a/sub") {
+		t.Fatalf("Tidy error = %v, want missing package", err)
+	}
+}
+
+func TestModuleProxyFetchTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := writeProxyModule(t, dir, "example.com/a", "v1.0.0", "module example.com/a\n\ngo 1.20\n", goSource("a"))
+	info, err := os.Stat(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	defer func(limits map[string]int64) { fetchLimits = limits }(fetchLimits)
+	proxy := &ModuleProxy{URL: server.URL}
+	m := module.Version{Path: "example.com/a", Version: "v1.0.0"}
+
+	fetchLimits = map[string]int64{".zip": info.Size()}
+	if data, err := proxy.fetch(context.Background(), m, ".zip"); err != nil || int64(len(data)) != info.Size() {
+		t.Errorf("fetch of a zip at the limit = %d bytes, %v", len(data), err)
+	}
+
+	fetchLimits = map[string]int64{".zip": info.Size() - 1}
+	if _, err := proxy.fetch(context.Background(), m, ".zip"); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("fetch of a zip past the limit error = %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Output receives the files of a generated project.
+// Paths are slash-separated and relative to the project root.
+type Output interface {
+	// Mkdir creates a directory and its parents
+	Mkdir(dir string) error
+	// WriteFile writes a file with permissions perm, creating its parent directories
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// DirOutput writes a project below a directory of the OS filesystem
+type DirOutput struct {
+	Dir string
+}
+
+// Mkdir implements Output
+func (o DirOutput) Mkdir(dir string) error {
+	return os.MkdirAll(filepath.Join(o.Dir, filepath.FromSlash(dir)), 0755)
+}
+
+// WriteFile implements Output
+func (o DirOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := o.Mkdir(path.Dir(name)); err != nil {
+		return err
+	}
+	file := filepath.Join(o.Dir, filepath.FromSlash(name))
+	if err := os.WriteFile(file, data, perm); err != nil {
+		return err
+	}
+	// WriteFile applies the umask, archives and the memory output do not
+	return os.Chmod(file, perm)
+}
+
+// MemOutput keeps a project in memory. It implements fs.FS, fs.ReadDirFS and fs.ReadFileFS
+// to read the project back.
+type MemOutput struct {
+	files memFS
+}
+
+// NewMemOutput creates an empty in-memory output
+func NewMemOutput() *MemOutput {
+	return &MemOutput{files: make(memFS)}
+}
+
+// Mkdir implements Output
+func (m *MemOutput) Mkdir(dir string) error {
+	for ; dir != "." && dir != "/" && m.files[dir] == nil; dir = path.Dir(dir) {
+		m.files[dir] = &memFile{Mode: fs.ModeDir | 0755}
+	}
+	return nil
+}
+
+// WriteFile implements Output
+func (m *MemOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := m.Mkdir(path.Dir(name)); err != nil {
+		return err
+	}
+	m.files[name] = &memFile{Data: data, Mode: perm.Perm()}
+	return nil
+}
+
+// Open implements fs.FS
+func (m *MemOutput) Open(name string) (fs.File, error) {
+	return m.files.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS
+func (m *MemOutput) ReadDir(name string) ([]fs.DirEntry, error) {
+	return m.files.ReadDir(name)
+}
+
+// ReadFile implements fs.ReadFileFS
+func (m *MemOutput) ReadFile(name string) ([]byte, error) {
+	return m.files.ReadFile(name)
+}
+
+// CopyTo writes every directory and file to out in path order
+func (m *MemOutput) CopyTo(out Output) error {
+	paths := make([]string, 0, len(m.files))
+	for p := range m.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		file := m.files[p]
+		var err error
+		if file.Mode.IsDir() {
+			err = out.Mkdir(p)
+		} else {
+			err = out.WriteFile(p, file.Data, file.Mode)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ArchiveTime is the modification time of every archive entry. Archives do not depend on when they
+// were written, so identical projects give identical bytes. It is the earliest time ZIP can store.
+var ArchiveTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ZipOutput writes a project straight into a ZIP archive, below a root directory (e.g. the project name)
+type ZipOutput struct {
+	w    *zip.Writer
+	root string
+	dirs map[string]bool // directories with an entry
+}
+
+// NewZipOutput creates an output adding entries to w below root; the caller closes w
+func NewZipOutput(w *zip.Writer, root string) *ZipOutput {
+	return &ZipOutput{
+		w:    w,
+		root: root,
+		dirs: make(map[string]bool),
+	}
+}
+
+// Mkdir implements Output
+func (z *ZipOutput) Mkdir(dir string) error {
+	if dir == "." || z.dirs[dir] {
+		return nil
+	}
+	if err := z.Mkdir(path.Dir(dir)); err != nil {
+		return err
+	}
+	z.dirs[dir] = true
+
+	header := &zip.FileHeader{Name: path.Join(z.root, dir) + "/", Modified: ArchiveTime}
+	header.SetMode(fs.ModeDir | 0755)
+	_, err := z.w.CreateHeader(header)
+	return err
+}
+
+// WriteFile implements Output
+func (z *ZipOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := z.Mkdir(path.Dir(name)); err != nil {
+		return err
+	}
+
+	header := &zip.FileHeader{Name: path.Join(z.root, name), Method: zip.Deflate, Modified: ArchiveTime}
+	header.SetMode(perm.Perm())
+	w, err := z.w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// TarOutput writes a project straight into a tar archive, below a root directory (e.g. the project name).
+// Unlike ZIP, tar keeps file modes for every unpacking tool.
+type TarOutput struct {
+	w    *tar.Writer
+	root string
+	dirs map[string]bool // directories with an entry
+}
+
+// NewTarOutput creates an output adding entries to w below root; the caller closes w
+func NewTarOutput(w *tar.Writer, root string) *TarOutput {
+	return &TarOutput{
+		w:    w,
+		root: root,
+		dirs: make(map[string]bool),
+	}
+}
+
+// Mkdir implements Output
+func (t *TarOutput) Mkdir(dir string) error {
+	if dir == "." || t.dirs[dir] {
+		return nil
+	}
+	if err := t.Mkdir(path.Dir(dir)); err != nil {
+		return err
+	}
+	t.dirs[dir] = true
+
+	return t.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     path.Join(t.root, dir) + "/",
+		Mode:     0755,
+		ModTime:  ArchiveTime,
+	})
+}
+
+// WriteFile implements Output
+func (t *TarOutput) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if err := t.Mkdir(path.Dir(name)); err != nil {
+		return err
+	}
+
+	err := t.w.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Join(t.root, name),
+		Size:     int64(len(data)),
+		Mode:     int64(perm.Perm()),
+		ModTime:  ArchiveTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = t.w.Write(data)
+	return err
+}
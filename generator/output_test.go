@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestZipOutput(t *testing.T) {
+	project := NewMemOutput()
+	config := ProjectConfig{
+		Name:       "demo-api",
+		ModulePath: "github.com/example/demo-api",
+		Structure:  "standard",
+		Libraries:  []string{"go-logger"},
+		Output:     project,
+	}
+	if err := GenerateProject(context.Background(), &config); err != nil {
+		t.Fatalf("GenerateProject: %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := project.CopyTo(NewZipOutput(zw, "demo-api")); err != nil {
+		t.Fatalf("CopyTo: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	// Every directory gets one entry before its files, including empty ones
+	for _, want := range []string{"demo-api/cmd/", "demo-api/cmd/server/", "demo-api/cmd/server/main.go", "demo-api/internal/models/", "demo-api/go.mod"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("archive has no %s: %v", want, names)
+		}
+	}
+	if slices.Index(names, "demo-api/cmd/") > slices.Index(names, "demo-api/cmd/server/main.go") {
+		t.Errorf("directory entry after its files: %v", names)
+	}
+
+	want := readTree(t, project, "")
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		if name := f.Name[len("demo-api/"):]; string(content) != want[name] {
+			t.Errorf("%s differs from the generated file", name)
+		}
+	}
+}
+
+func TestGenerateProjectFailureWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	config := ProjectConfig{
+		Name:       "demo-api",
+		ModulePath: "github.com/example/demo api", // generated imports do not resolve
+		Structure:  "standard",
+		OutputDir:  dir,
+		Verify:     true,
+	}
+	var phaseErr *PhaseError
+	if err := GenerateProject(context.Background(), &config); !errors.As(err, &phaseErr) || phaseErr.Phase != PhaseVerify {
+		t.Fatalf("GenerateProject error = %v, want a %s phase error", err, PhaseVerify)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("failed generation left %d entries in the output directory", len(entries))
+	}
+}
+
+func TestGenerateProjectCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	config := ProjectConfig{
+		Name:       "demo-api",
+		ModulePath: "github.com/example/demo-api",
+		Output:     NewMemOutput(),
+		Progress: func(event ProgressEvent) {
+			if event.Phase == PhaseHandlers {
+				cancel()
+			}
+		},
+	}
+	var phaseErr *PhaseError
+	err := GenerateProject(ctx, &config)
+	if !errors.As(err, &phaseErr) || phaseErr.Phase != PhaseLibraries || !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateProject error = %v, want a canceled %s phase error", err, PhaseLibraries)
+	}
+	if files := readTree(t, config.Output.(*MemOutput), ""); len(files) != 0 {
+		t.Errorf("canceled generation wrote %d files", len(files))
+	}
+}
+
+func TestOutputModes(t *testing.T) {
+	project := NewMemOutput()
+	if err := project.WriteFile("scripts/run.sh", []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := project.WriteFile("README.md", []byte("# demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := project.CopyTo(NewTarOutput(tw, "demo-api")); err != nil {
+		t.Fatalf("CopyTo tar: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	modes := make(map[string]int64)
+	for tr := tar.NewReader(&buf); ; {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		modes[header.Name] = header.Mode
+	}
+	want := map[string]int64{"demo-api/README.md": 0644, "demo-api/scripts/": 0755, "demo-api/scripts/run.sh": 0755}
+	if !maps.Equal(modes, want) {
+		t.Errorf("tar modes = %v, want %v", modes, want)
+	}
+
+	dir := t.TempDir()
+	if err := project.CopyTo(DirOutput{Dir: dir}); err != nil {
+		t.Fatalf("CopyTo dir: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh mode = %v, want 0755", info.Mode())
+	}
+}
+
+func TestMemOutputFS(t *testing.T) {
+	project := NewMemOutput()
+	for name, data := range map[string]string{"main.go": "package main\n", "config/config.go": "package config\n", "scripts/run.sh": "#!/bin/sh\n"} {
+		if err := project.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := project.Mkdir("migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(project, "main.go", "config/config.go", "scripts/run.sh", "migrations"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,128 @@
+package generator
+
+import "io/fs"
+
+// LibraryPlugin describes everything a catalog library contributes to a generated project.
+// Snippets are Go source indented for the body of main().
+type LibraryPlugin interface {
+	// Name returns the catalog name of the library (e.g. "go-logger")
+	Name() string
+	// Imports returns import paths added to main.go
+	Imports(data *TemplateData) []string
+	// MainInit returns the main.go snippet run after the config is loaded
+	MainInit(data *TemplateData) string
+	// MainRoutes returns the main.go snippet run after the router is created
+	MainRoutes(data *TemplateData) string
+	// ConfigFields returns fields added to config.Config
+	ConfigFields(data *TemplateData) []ConfigField
+	// EnvVars returns variables added to .env and .env.example
+	EnvVars(data *TemplateData) []EnvVar
+	// Files returns extra files rendered from templates
+	Files(data *TemplateData) []PluginFile
+	// Directories returns extra directories created in the project
+	Directories(data *TemplateData) []string
+	// Requirements returns go.mod requirements
+	Requirements(data *TemplateData) []Requirement
+}
+
+// ConfigField is a string field of the generated config.Config loaded from the environment
+type ConfigField struct {
+	Name    string // Go field name (e.g. "LogLevel")
+	Env     string // Environment variable (e.g. "LOG_LEVEL")
+	Default string // Value used when the variable is unset
+}
+
+// EnvVar is a line of the generated .env files
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// PluginFile is an extra file rendered from a template
+type PluginFile struct {
+	Path     string      // Path relative to the project root
+	Template string      // Template name (e.g. "middleware.go.tmpl")
+	Mode     fs.FileMode // File permissions (e.g. 0755 for scripts); zero means 0644
+}
+
+// Requirement is a go.mod requirement
+type Requirement struct {
+	Path    string
+	Version string
+}
+
+// registry holds one plugin per catalog library.
+// Order matters: snippets and imports are emitted in this order, so the logger comes first.
+var registry = []LibraryPlugin{
+	loggerPlugin{basePlugin{"go-logger"}},
+	authPlugin{basePlugin{"go-auth"}},
+	migrationPlugin{basePlugin{"go-migration"}},
+	cachePlugin{basePlugin{"go-cache"}},
+	swaggerPlugin{basePlugin{"go-swagger"}},
+	responsePlugin{basePlugin{"go-response"}},
+	validatorPlugin{basePlugin{"go-validator"}},
+	paginationPlugin{basePlugin{"go-pagination"}},
+	websocketPlugin{basePlugin{"go-websocket"}},
+	metricsPlugin{basePlugin{"go-metrics"}},
+}
+
+// Plugins returns all registered library plugins
+func Plugins() []LibraryPlugin {
+	return registry
+}
+
+// LookupPlugin returns the plugin registered for a library
+func LookupPlugin(name string) (LibraryPlugin, bool) {
+	for _, p := range registry {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// selectedPlugins returns the plugins of the selected libraries in registry order.
+// Libraries without a registered plugin only contribute their go.mod requirement.
+func selectedPlugins(libraries []string) []LibraryPlugin {
+	selected := make(map[string]bool, len(libraries))
+	for _, lib := range libraries {
+		selected[lib] = true
+	}
+
+	var result []LibraryPlugin
+	for _, p := range registry {
+		if selected[p.Name()] {
+			result = append(result, p)
+			delete(selected, p.Name())
+		}
+	}
+
+	// Keep request order for unknown libraries
+	for _, lib := range libraries {
+		if selected[lib] {
+			result = append(result, basePlugin{name: lib})
+			delete(selected, lib)
+		}
+	}
+
+	return result
+}
+
+// basePlugin provides the default contributions of a library: its go.mod requirement and nothing else.
+// Plugins embed it and override what they need.
+type basePlugin struct {
+	name string
+}
+
+func (p basePlugin) Name() string                           { return p.name }
+func (basePlugin) Imports(*TemplateData) []string           { return nil }
+func (basePlugin) MainInit(*TemplateData) string            { return "" }
+func (basePlugin) MainRoutes(*TemplateData) string          { return "" }
+func (basePlugin) ConfigFields(*TemplateData) []ConfigField { return nil }
+func (basePlugin) EnvVars(*TemplateData) []EnvVar           { return nil }
+func (basePlugin) Files(*TemplateData) []PluginFile         { return nil }
+func (basePlugin) Directories(*TemplateData) []string       { return nil }
+
+func (p basePlugin) Requirements(data *TemplateData) []Requirement {
+	return []Requirement{{Path: data.LibraryPath(p.name), Version: data.Version(p.name)}}
+}
@@ -0,0 +1,41 @@
+package generator
+
+import "fmt"
+
+// Phases of GenerateProject, in the order they run
+const (
+	PhaseStructure    = "structure"    // Directory structure
+	PhaseGoMod        = "go.mod"       // go.mod
+	PhaseMain         = "main.go"      // main.go
+	PhaseConfig       = "config"       // config/config.go
+	PhaseHandlers     = "handlers"     // Handlers
+	PhaseLibraries    = "libraries"    // Files contributed by library plugins (e.g. middleware)
+	PhaseEnv          = "env"          // .env and .env.example
+	PhaseGitignore    = "gitignore"    // .gitignore
+	PhaseRailway      = "railway"      // railway.json, for Railway deployments
+	PhaseReadme       = "readme"       // README.md
+	PhaseDependencies = "dependencies" // Tidied go.mod and go.sum, with a ModuleProxy
+	PhaseVerify       = "verify"       // Type-check, with Verify
+	PhaseWrite        = "write"        // Copy to the output
+)
+
+// ProgressEvent reports the phase GenerateProject starts
+type ProgressEvent struct {
+	Phase string // One of the Phase constants
+	Step  int    // Phases completed before this one
+	Steps int    // Phases of this generation; optional phases are only counted when they run
+}
+
+// PhaseError is the error of the phase a generation failed in
+type PhaseError struct {
+	Phase string
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Phase, e.Err)
+}
+
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"io/fs"
+	"strings"
+	"text/template"
+
+	"github.com/OkanUysal/go-starter-api/catalog"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates holds every output template, keyed by file name (e.g. "main.go.tmpl")
+var templates = template.Must(
+	template.New("").
+		Funcs(template.FuncMap{
+			"trimPrefix": strings.TrimPrefix,
+		}).
+		ParseFS(templateFS, "templates/*.tmpl"),
+)
+
+// TemplateData is the data model passed to every output template
+type TemplateData struct {
+	Name        string
+	ModulePath  string
+	Structure   string
+	Database    string
+	Deployment  string
+	Libraries   []string
+	Versions    map[string]string
+	Paths       map[string]string
+	Standard    bool   // Structure is "standard"
+	HasDatabase bool   // Database is not "none"
+	MainPath    string // Entrypoint path relative to the project root
+	Tidy        bool   // go.mod and go.sum are resolved, no "go mod tidy" needed
+
+	// Contributions of the selected library plugins
+	Plugins      []LibraryPlugin
+	Imports      []string
+	MainInit     []string
+	MainRoutes   []string
+	ConfigFields []ConfigField
+	EnvVars      []EnvVar
+	Files        []PluginFile
+	Directories  []string
+	Requirements []Requirement
+}
+
+// newTemplateData derives the template data model from a project config
+func newTemplateData(config *ProjectConfig) *TemplateData {
+	data := &TemplateData{
+		Name:        config.Name,
+		ModulePath:  config.ModulePath,
+		Structure:   config.Structure,
+		Database:    config.Database,
+		Deployment:  config.Deployment,
+		Libraries:   config.Libraries,
+		Versions:    config.Versions,
+		Paths:       config.Paths,
+		Standard:    config.Structure == "standard",
+		HasDatabase: config.Database != "none",
+		MainPath:    "main.go",
+		Tidy:        config.ModuleProxy != "",
+	}
+
+	if data.Standard {
+		data.MainPath = "cmd/server/main.go"
+	}
+
+	data.Plugins = selectedPlugins(config.Libraries)
+	for _, p := range data.Plugins {
+		data.Imports = append(data.Imports, p.Imports(data)...)
+		if snippet := p.MainInit(data); snippet != "" {
+			data.MainInit = append(data.MainInit, snippet)
+		}
+		if snippet := p.MainRoutes(data); snippet != "" {
+			data.MainRoutes = append(data.MainRoutes, snippet)
+		}
+		data.ConfigFields = append(data.ConfigFields, p.ConfigFields(data)...)
+		data.EnvVars = append(data.EnvVars, p.EnvVars(data)...)
+		data.Files = append(data.Files, p.Files(data)...)
+		data.Directories = append(data.Directories, p.Directories(data)...)
+		data.Requirements = append(data.Requirements, p.Requirements(data)...)
+	}
+
+	return data
+}
+
+// Has checks if a library is selected
+func (d *TemplateData) Has(lib string) bool {
+	for _, l := range d.Libraries {
+		if l == lib {
+			return true
+		}
+	}
+	return false
+}
+
+// Version returns the go.mod version of a library: the configured version,
+// else the catalog fallback version
+func (d *TemplateData) Version(lib string) string {
+	if v := d.Versions[lib]; v != "" {
+		return v
+	}
+	if v := catalog.DefaultManifest().Version(lib); v != "" {
+		return v
+	}
+	return "v1.0.0"
+}
+
+// LibraryPath returns the module path of a library: the configured path, else the path in the
+// built-in catalog, else the OkanUysal repository of that name
+func (d *TemplateData) LibraryPath(lib string) string {
+	if p := d.Paths[lib]; p != "" {
+		return p
+	}
+	if p := catalog.DefaultManifest().ModulePath(lib); p != "" {
+		return p
+	}
+	return "github.com/OkanUysal/" + lib
+}
+
+// renderTemplate executes the named template with the given data
+func renderTemplate(name string, data *TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeTemplate renders the named template and writes it to path inside the project.
+// Go files are parse-checked and gofmt'ed before they are written.
+func writeTemplate(config *ProjectConfig, project *MemOutput, path, name string) error {
+	return writeTemplateMode(config, project, path, name, 0644)
+}
+
+// writeTemplateMode is writeTemplate for files with other permissions than 0644
+func writeTemplateMode(config *ProjectConfig, project *MemOutput, path, name string, perm fs.FileMode) error {
+	content, err := renderTemplate(name, newTemplateData(config))
+	if err != nil {
+		return err
+	}
+
+	if isGoFile(path) {
+		if content, err = formatGoSource(path, content); err != nil {
+			return err
+		}
+	}
+
+	return project.WriteFile(path, []byte(content), perm)
+}
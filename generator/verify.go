@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// stubFS holds type-checking stubs of the packages generated projects import: the OkanUysal libraries
+// by library name (e.g. _stubs/libraries/go-logger), and gin and the standard library packages used
+// by the templates by import path (e.g. _stubs/packages/github.com/gin-gonic/gin, _stubs/packages/os).
+//
+//go:embed _stubs
+var stubFS embed.FS
+
+const (
+	libraryStubs = "_stubs/libraries"
+	packageStubs = "_stubs/packages"
+)
+
+// VerifyProject type-checks every package of a generated project.
+// Project packages are read from root and imported packages from the embedded stubs, so neither
+// network access, module downloads nor the Go sources of the standard library are needed.
+// libraries maps the library names of the project to the module paths it imports them from,
+// so libraries keep their stub when their path is overridden.
+func VerifyProject(root fs.FS, modulePath string, libraries map[string]string) error {
+	v := &verifier{
+		fset:     token.NewFileSet(),
+		project:  root,
+		module:   modulePath,
+		stubs:    make(map[string]string),
+		packages: make(map[string]*types.Package),
+	}
+	for lib, importPath := range libraries {
+		if dir := path.Join(libraryStubs, lib); isDir(stubFS, dir) {
+			v.stubs[importPath] = dir
+		}
+	}
+
+	dirs, err := goPackageDirs(root)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, dir := range dirs {
+		importPath := modulePath
+		if dir != "." {
+			importPath = modulePath + "/" + dir
+		}
+		if _, err := v.Import(importPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("generated project failed verification: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// libraryPaths maps the libraries of a project to the module paths its code imports them from
+func libraryPaths(config *ProjectConfig) map[string]string {
+	data := &TemplateData{Paths: config.Paths}
+	paths := make(map[string]string, len(config.Libraries))
+	for _, lib := range config.Libraries {
+		paths[lib] = data.LibraryPath(lib)
+	}
+	return paths
+}
+
+// verifier resolves imports of a generated project and caches checked packages
+type verifier struct {
+	fset     *token.FileSet
+	project  fs.FS
+	module   string
+	stubs    map[string]string // Stub directories of the libraries, by import path
+	packages map[string]*types.Package
+	failed   map[string]bool
+}
+
+// Import implements types.Importer
+func (v *verifier) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := v.packages[importPath]; ok {
+		return pkg, nil
+	}
+	if v.failed[importPath] {
+		return nil, fmt.Errorf("package %s has errors", importPath)
+	}
+
+	var (
+		pkg *types.Package
+		err error
+	)
+
+	switch {
+	case importPath == v.module || strings.HasPrefix(importPath, v.module+"/"):
+		dir := strings.TrimPrefix(strings.TrimPrefix(importPath, v.module), "/")
+		if dir == "" {
+			dir = "."
+		}
+		pkg, err = v.check(importPath, v.project, dir)
+	case v.stubs[importPath] != "":
+		pkg, err = v.check(importPath, stubFS, v.stubs[importPath])
+	case isDir(stubFS, path.Join(packageStubs, importPath)):
+		pkg, err = v.check(importPath, stubFS, path.Join(packageStubs, importPath))
+	default:
+		err = fmt.Errorf("no verification stub for package %s", importPath)
+	}
+
+	if err != nil {
+		if v.failed == nil {
+			v.failed = make(map[string]bool)
+		}
+		v.failed[importPath] = true
+		return nil, err
+	}
+
+	v.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// check parses and type-checks the Go files of dir in fsys
+func (v *verifier) check(importPath string, fsys fs.FS, dir string) (*types.Package, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		if entry.IsDir() || !isGoFile(entry.Name()) || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		name := path.Join(dir, entry.Name())
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := parser.ParseFile(v.fset, name, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	var errs []error
+	conf := types.Config{
+		Importer: v,
+		Error: func(err error) {
+			errs = append(errs, err)
+		},
+	}
+
+	pkg, _ := conf.Check(importPath, v.fset, files, nil)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return pkg, nil
+}
+
+// goPackageDirs returns every directory of root containing Go files, sorted
+func goPackageDirs(root fs.FS) ([]string, error) {
+	seen := make(map[string]bool)
+	err := fs.WalkDir(root, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isGoFile(p) {
+			seen[path.Dir(p)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// isDir checks if a directory exists in fsys
+func isDir(fsys fs.FS, dir string) bool {
+	info, err := fs.Stat(fsys, dir)
+	return err == nil && info.IsDir()
+}
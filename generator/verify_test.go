@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestVerifyProject(t *testing.T) {
+	project := fstest.MapFS{
+		"main.go": {Data: []byte(`package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.com/acme/go-logger"
+)
+
+func main() {
+	logger.Info("starting")
+	router := gin.Default()
+	log.Fatal(router.Run(":" + os.Getenv("PORT")))
+}
+`)},
+	}
+	if err := VerifyProject(project, "example.com/demo", map[string]string{"go-logger": "gitlab.com/acme/go-logger"}); err != nil {
+		t.Errorf("VerifyProject: %v", err)
+	}
+}
+
+func TestVerifyProjectErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		libraries map[string]string
+		want      string
+	}{
+		{"library not in the project", `import "github.com/OkanUysal/go-logger"`, nil, "no verification stub for package github.com/OkanUysal/go-logger"},
+		{"package without stub", `import "net/http"`, nil, "no verification stub for package net/http"},
+		{"type error", `import "os"
+
+var port int = os.Getenv("PORT")`, nil, "cannot use os.Getenv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := fstest.MapFS{"main.go": {Data: []byte("package main\n\n" + tt.src + "\n")}}
+			err := VerifyProject(project, "example.com/demo", tt.libraries)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("VerifyProject error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/OkanUysal/go-starter-api/generator"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// archiveFormat is a download format of generated projects
+type archiveFormat struct {
+	Name        string // Value of the format option, also the file extension
+	ContentType string
+	// write writes an archive of the project to w, with every file below a root directory
+	write func(w io.Writer, project *generator.MemOutput, root string) error
+}
+
+// archiveFormats are the download formats, the first one is the default.
+// Every writer gives the same bytes for the same project.
+var archiveFormats = []archiveFormat{
+	{Name: "zip", ContentType: "application/zip", write: writeZip},
+	{Name: "tar.gz", ContentType: "application/gzip", write: writeTarGz},
+	{Name: "tar.zst", ContentType: "application/zstd", write: writeTarZst},
+}
+
+// formatNames returns the names of the archive formats
+func formatNames() []string {
+	names := make([]string, len(archiveFormats))
+	for i, f := range archiveFormats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// negotiateFormat returns the requested archive format. Without a format option the Accept header
+// picks one, and the default applies when it accepts none of them.
+func negotiateFormat(c *gin.Context, name string) archiveFormat {
+	if name == "" {
+		contentTypes := make([]string, len(archiveFormats))
+		for i, f := range archiveFormats {
+			contentTypes[i] = f.ContentType
+		}
+		accepted := c.NegotiateFormat(contentTypes...)
+		for _, f := range archiveFormats {
+			if f.ContentType == accepted {
+				return f
+			}
+		}
+	}
+	for _, f := range archiveFormats {
+		if f.Name == name {
+			return f
+		}
+	}
+	return archiveFormats[0]
+}
+
+// maxArchiveSize bounds the size of an archive. Archives are built in memory to be hashed and cached
+// before they are sent, so a larger project fails instead of holding an unbounded buffer.
+const maxArchiveSize = 64 << 20
+
+// maxArchiveBuilds bounds the archives built at the same time, so the memory held by builds stays
+// below maxArchiveBuilds*maxArchiveSize however many requests arrive
+const maxArchiveBuilds = 4
+
+// archiveBuilds holds a slot per archive being built
+var archiveBuilds = make(chan struct{}, maxArchiveBuilds)
+
+// errArchiveTooLarge is returned when an archive exceeds maxArchiveSize
+var errArchiveTooLarge = fmt.Errorf("archive is larger than %d MB", maxArchiveSize>>20)
+
+// newArchive packages a generated project in memory, with every file below a root directory
+func newArchive(project *generator.MemOutput, format archiveFormat, root string) (*projectArchive, error) {
+	var buf bytes.Buffer
+	if err := format.write(&limitedWriter{w: &buf, n: maxArchiveSize}, project, root); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return &projectArchive{
+		Data:     buf.Bytes(),
+		SHA256:   hex.EncodeToString(sum[:]),
+		Format:   format,
+		FileName: root + "." + format.Name,
+	}, nil
+}
+
+// limitedWriter writes to w until n bytes were written, then fails with errArchiveTooLarge
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write implements io.Writer
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, errArchiveTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// writeZip writes a ZIP archive of the project to w
+func writeZip(w io.Writer, project *generator.MemOutput, root string) error {
+	archive := zip.NewWriter(w)
+	if err := project.CopyTo(generator.NewZipOutput(archive, root)); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeTarGz writes a gzip-compressed tar archive of the project to w
+func writeTarGz(w io.Writer, project *generator.MemOutput, root string) error {
+	gz := gzip.NewWriter(w)
+	if err := writeTar(gz, project, root); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeTarZst writes a zstd-compressed tar archive of the project to w
+func writeTarZst(w io.Writer, project *generator.MemOutput, root string) error {
+	zw, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return err
+	}
+	if err := writeTar(zw, project, root); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// writeTar writes an uncompressed tar archive of the project to w
+func writeTar(w io.Writer, project *generator.MemOutput, root string) error {
+	archive := tar.NewWriter(w)
+	if err := project.CopyTo(generator.NewTarOutput(archive, root)); err != nil {
+		return err
+	}
+	return archive.Close()
+}
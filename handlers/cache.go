@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/OkanUysal/go-starter-api/generator"
+)
+
+// defaultArchiveCacheSize bounds the bytes of archives kept by the archive cache
+const defaultArchiveCacheSize = 64 << 20
+
+// archives caches generated archives by archiveKey
+var archives = newArchiveCache(defaultArchiveCacheSize)
+
+// projectArchive is a generated project archive
+type projectArchive struct {
+	Data     []byte
+	SHA256   string // Hex SHA-256 of Data, also the ETag
+	Format   archiveFormat
+	FileName string
+}
+
+// archiveCache is a least recently used cache of archives bounded by their total size
+type archiveCache struct {
+	mu      sync.Mutex
+	maxSize int
+	size    int
+	lru     *list.List // *cacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+// cacheEntry is an archive in the cache
+type cacheEntry struct {
+	key     string
+	archive *projectArchive
+}
+
+// newArchiveCache creates a cache keeping at most maxSize bytes of archives; zero disables it
+func newArchiveCache(maxSize int) *archiveCache {
+	return &archiveCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the archive cached for key
+func (c *archiveCache) Get(key string) (*projectArchive, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).archive, true
+}
+
+// Add caches an archive, evicting the least recently used ones to stay within the size bound.
+// Archives larger than the bound are not cached.
+func (c *archiveCache) Add(key string, a *projectArchive) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(a.Data) > c.maxSize {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.size -= len(elem.Value.(*cacheEntry).archive.Data)
+		c.lru.Remove(elem)
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, archive: a})
+	c.size += len(a.Data)
+
+	for c.size > c.maxSize {
+		oldest := c.lru.Back()
+		entry := oldest.Value.(*cacheEntry)
+		c.lru.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= len(entry.archive.Data)
+	}
+}
+
+// archiveKey returns the content address of the archive of a project: a hash of the project config
+// with its defaults applied and only the versions and paths of the selected libraries, and the format.
+// Equal keys give byte-identical archives. Verification is part of the key, so a request asking for
+// it never gets an archive cached by a request that skipped it.
+func archiveKey(config generator.ProjectConfig, format string) string {
+	config.ApplyDefaults()
+
+	versions := make(map[string]string, len(config.Libraries))
+	paths := make(map[string]string, len(config.Libraries))
+	for _, lib := range config.Libraries {
+		versions[lib] = config.Versions[lib]
+		paths[lib] = config.Paths[lib]
+	}
+
+	// Libraries keep their order, it is the order of the generated README
+	data, _ := json.Marshal(struct {
+		Name        string            `json:"name"`
+		ModulePath  string            `json:"modulePath"`
+		Structure   string            `json:"structure"`
+		Database    string            `json:"database"`
+		Libraries   []string          `json:"libraries"`
+		Versions    map[string]string `json:"versions"`
+		Paths       map[string]string `json:"paths"`
+		Deployment  string            `json:"deployment"`
+		Verify      bool              `json:"verify"`
+		ModuleProxy string            `json:"moduleProxy"`
+		Format      string            `json:"format"`
+	}{
+		config.Name, config.ModulePath, config.Structure, config.Database, config.Libraries, versions, paths,
+		config.Deployment, config.Verify, config.ModuleProxy, format,
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/generator"
+)
+
+func TestArchiveCache(t *testing.T) {
+	cache := newArchiveCache(10)
+	add := func(key string, size int) { cache.Add(key, &projectArchive{Data: make([]byte, size)}) }
+
+	add("a", 4)
+	add("b", 4)
+	if _, ok := cache.Get("a"); !ok { // a is now the most recently used
+		t.Fatal("a is not cached")
+	}
+	add("c", 4) // evicts b
+	add("d", 11)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": false} {
+		if _, ok := cache.Get(key); ok != want {
+			t.Errorf("cached %s = %v, want %v", key, ok, want)
+		}
+	}
+	if cache.size != 8 {
+		t.Errorf("size = %d, want 8", cache.size)
+	}
+}
+
+func TestArchiveKey(t *testing.T) {
+	base := generator.ProjectConfig{
+		Name:       "demo-api",
+		ModulePath: "github.com/example/demo-api",
+		Libraries:  []string{"go-logger"},
+		Versions:   map[string]string{"go-logger": "v1.0.0", "go-auth": "v1.0.0"},
+	}
+	key := archiveKey(base, "zip")
+
+	same := base
+	same.Structure, same.Database, same.Deployment = "simple", "none", "railway"
+	same.Versions = map[string]string{"go-logger": "v1.0.0", "go-auth": "v2.0.0"} // not selected
+	if archiveKey(same, "zip") != key {
+		t.Error("defaults or versions of unselected libraries change the key")
+	}
+
+	for name, config := range map[string]generator.ProjectConfig{
+		"version": {Name: base.Name, ModulePath: base.ModulePath, Libraries: base.Libraries, Versions: map[string]string{"go-logger": "v1.1.0"}},
+		"name":    {Name: "other", ModulePath: base.ModulePath, Libraries: base.Libraries, Versions: base.Versions},
+		"verify":  {Name: base.Name, ModulePath: base.ModulePath, Libraries: base.Libraries, Versions: base.Versions, Verify: true},
+	} {
+		if archiveKey(config, "zip") == key {
+			t.Errorf("%s does not change the key", name)
+		}
+	}
+	if archiveKey(base, "tar.gz") == key {
+		t.Error("format does not change the key")
+	}
+}
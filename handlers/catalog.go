@@ -0,0 +1,17 @@
+package handlers
+
+import "github.com/OkanUysal/go-starter-api/catalog"
+
+var Catalog *catalog.Service
+
+func SetCatalog(c *catalog.Service) {
+	Catalog = c
+}
+
+// libraryManifest returns the manifest of the catalog, or the built-in manifest when no catalog is set
+func libraryManifest() *catalog.Manifest {
+	if Catalog == nil {
+		return catalog.DefaultManifest()
+	}
+	return Catalog.Manifest()
+}
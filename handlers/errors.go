@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// respondError aborts the request with the error envelope shared by all endpoints
+func respondError(c *gin.Context, status int, apiErr types.APIError) {
+	c.AbortWithStatusJSON(status, types.ErrorResponse{
+		Success: false,
+		Error:   apiErr,
+	})
+}
+
+// detailsError returns an error whose message and field are the ones of its first detail
+func detailsError(code string, details []types.ErrorDetail) types.APIError {
+	return types.APIError{
+		Code:    code,
+		Message: details[0].Message,
+		Field:   details[0].Field,
+		Details: details,
+	}
+}
+
+// bodyError describes a request body that cannot be decoded, on the field at fault when it is known
+func bodyError(err error) types.APIError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return types.APIError{
+			Code:    types.CodeInvalidBody,
+			Message: fmt.Sprintf("Invalid request body: %s must be %s, not %s", typeErr.Field, typeErr.Type, typeErr.Value),
+			Field:   typeErr.Field,
+		}
+	}
+	return types.APIError{
+		Code:    types.CodeInvalidBody,
+		Message: "Invalid request body",
+	}
+}
+
+// apiError returns err when it is an API error, and an internal error otherwise
+func apiError(err error) types.APIError {
+	var apiErr types.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return types.APIError{Code: types.CodeInternal, Message: "Internal server error"}
+}
+
+// NotFound responds to requests without a route
+func NotFound(c *gin.Context) {
+	respondError(c, http.StatusNotFound, types.APIError{
+		Code:    types.CodeNotFound,
+		Message: fmt.Sprintf("No endpoint %s %s", c.Request.Method, c.Request.URL.Path),
+	})
+}
+
+// MethodNotAllowed responds to requests with a method the route does not support
+func MethodNotAllowed(c *gin.Context) {
+	respondError(c, http.StatusMethodNotAllowed, types.APIError{
+		Code:    types.CodeMethodNotAllowed,
+		Message: fmt.Sprintf("%s is not supported by %s", c.Request.Method, c.Request.URL.Path),
+	})
+}
+
+// Recover responds to a panicking handler with an internal error; use with gin.CustomRecovery
+func Recover(c *gin.Context, recovered any) {
+	logger.Error("Request panicked", logger.String("path", c.Request.URL.Path), logger.String("panic", fmt.Sprint(recovered)))
+	respondError(c, http.StatusInternalServerError, types.APIError{
+		Code:    types.CodeInternal,
+		Message: "Internal server error",
+	})
+}
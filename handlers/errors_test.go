@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter returns the API routes with the error handlers of main.go
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(Recover))
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(NotFound)
+	r.NoMethod(MethodNotAllowed)
+	r.GET("/api/libraries", GetLibraries)
+	r.POST("/api/generate", RefuseWhenDraining, GenerateProject)
+	r.POST("/api/preview", RefuseWhenDraining, PreviewProject)
+	r.POST("/api/jobs", RefuseWhenDraining, CreateJob)
+	r.GET("/api/jobs/:id", GetJob)
+	r.GET("/api/jobs/:id/artifact", GetJobArtifact)
+	r.GET("/api/jobs/:id/events", JobEvents)
+	r.GET("/panic", func(*gin.Context) { panic("boom") })
+	return r
+}
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		status  int
+		code    string
+		field   string
+		details []string // code@field
+	}{
+		{"malformed body", "POST", "/api/generate", `{"name": `, 400, types.CodeInvalidBody, "", nil},
+		{"wrong type", "POST", "/api/generate", `{"name": "demo", "libraries": "go-auth"}`, 400, types.CodeInvalidBody, "libraries", nil},
+		{
+			"validation", "POST", "/api/generate",
+			`{"name": "../x", "modulePath": "github.com/example/demo", "libraries": ["go-auth", "go-orm"]}`,
+			400, types.CodeValidationFailed, "name",
+			[]string{types.CodeInvalidFormat + "@name", types.CodeUnknownLibrary + "@libraries[1]"},
+		},
+		{
+			"rules", "POST", "/api/generate",
+			`{"name": "demo", "modulePath": "github.com/example/demo", "libraries": ["go-logger", "go-migration"]}`,
+			400, types.CodeRuleViolation, "database.type",
+			[]string{types.CodeRequiresDatabase + "@database.type"},
+		},
+		{"query", "GET", "/api/libraries?prerelease=maybe", "", 400, types.CodeInvalidQuery, "prerelease", nil},
+		{"unknown source", "GET", "/api/libraries?source=acme", "", 400, types.CodeInvalidQuery, "source", nil},
+		{"not found", "GET", "/api/nothing", "", 404, types.CodeNotFound, "", nil},
+		{"method", "DELETE", "/api/libraries", "", 405, types.CodeMethodNotAllowed, "", nil},
+		{"panic", "GET", "/panic", "", 500, types.CodeInternal, "", nil},
+	}
+
+	router := newTestRouter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			var resp types.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode %s: %v", rec.Body, err)
+			}
+			if resp.Success || resp.Error.Code != tt.code || resp.Error.Field != tt.field || resp.Error.Message == "" {
+				t.Errorf("error = %+v, want code %s on field %q", resp.Error, tt.code, tt.field)
+			}
+
+			var details []string
+			for _, d := range resp.Error.Details {
+				details = append(details, d.Code+"@"+d.Field)
+			}
+			if strings.Join(details, " ") != strings.Join(tt.details, " ") {
+				t.Errorf("details = %v, want %v", details, tt.details)
+			}
+		})
+	}
+}
+
+func TestGetLibraries(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/api/libraries?source=builtin", nil))
+
+	var resp types.LibrariesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusOK || !resp.Success || resp.Count != 10 || len(resp.Data) != 10 || strings.Join(resp.Sources, ",") != "builtin" {
+		t.Errorf("GET /api/libraries = %d %+v", rec.Code, resp)
+	}
+}
@@ -1,188 +1,220 @@
 package handlers
 
 import (
-	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"time"
+	"mime"
 
 	"github.com/OkanUysal/go-logger"
 	"github.com/OkanUysal/go-starter-api/generator"
+	"github.com/OkanUysal/go-starter-api/jobs"
 	"github.com/OkanUysal/go-starter-api/types"
 	"github.com/gin-gonic/gin"
 )
 
-// GenerateProject generates a new project and returns a ZIP file
+// GenerateProject generates a new project and returns it as a ZIP, tar.gz or tar.zst archive
 // @Summary      Generate a new Go project
-// @Description  Generates a new Go project with selected libraries and configuration, returns a ZIP file
+// @Description  Generates a new Go project with selected libraries and configuration, returns an archive.
+// @Description  The format option selects zip, tar.gz or tar.zst; without it the Accept header picks one, defaulting to zip.
+// @Description  tar archives keep file modes. Archives are reproducible: identical configurations give identical bytes,
+// @Description  whose SHA-256 is sent as ETag and X-Archive-SHA256, and are served from a cache after the first request.
 // @Tags         Generator
 // @Accept       json
-// @Produce      application/zip
+// @Produce      application/zip,application/gzip,application/zstd
 // @Param        request  body      types.GenerateRequest  true  "Project configuration"
-// @Success      200      {file}    binary                 "ZIP file download"
-// @Failure      400      {object}  types.GenerateResponse "Bad request"
-// @Failure      500      {object}  types.GenerateResponse "Internal server error"
+// @Success      200      {file}    binary                 "Archive download"
+// @Header       200      {string}  ETag                   "Quoted SHA-256 of the archive"
+// @Header       200      {string}  X-Archive-SHA256       "Hex SHA-256 of the archive"
+// @Header       200      {string}  X-Cache                "HIT when served from the archive cache, else MISS"
+// @Failure      400      {object}  types.ErrorResponse  "invalid_body, validation_failed or rule_violation, with details per field"
+// @Failure      500      {object}  types.ErrorResponse  "generation_failed or archive_failed"
+// @Failure      503      {object}  types.ErrorResponse  "shutting_down"
 // @Router       /generate [post]
 func GenerateProject(c *gin.Context) {
 	var req types.GenerateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error("Invalid request body", logger.Err(err))
-		c.JSON(400, types.GenerateResponse{
-			Success: false,
-			Error:   "Invalid request body",
-		})
+		respondError(c, 400, bodyError(err))
 		return
 	}
 
 	logger.Info("Generating project", logger.String("name", req.Name), logger.String("modulePath", req.ModulePath))
 
-	// Validate request
-	if req.Name == "" {
-		logger.Warn("Project name is required")
-		c.JSON(400, types.GenerateResponse{
-			Success: false,
-			Error:   "Project name is required",
-		})
+	config, ok := projectConfig(c, &req)
+	if !ok {
 		return
 	}
 
-	if req.ModulePath == "" {
-		logger.Warn("Module path is required")
-		c.JSON(400, types.GenerateResponse{
-			Success: false,
-			Error:   "Module path is required",
-		})
+	format := negotiateFormat(c, req.Format)
+	archive, cached, err := buildArchive(c.Request.Context(), config, format, nil)
+	if err != nil {
+		respondError(c, 500, apiError(err))
 		return
 	}
 
-	// Create temporary directory for project
-	tempDir := filepath.Join("temp", fmt.Sprintf("%s_%d", req.Name, time.Now().Unix()))
-	projectDir := filepath.Join(tempDir, req.Name)
+	cacheStatus := "MISS"
+	if cached {
+		cacheStatus = "HIT"
+	}
+	serveArchive(c, archive, cacheStatus)
+}
 
-	defer func() {
-		// Cleanup temp directory after some time
-		time.AfterFunc(10*time.Minute, func() {
-			os.RemoveAll(tempDir)
-		})
-	}()
+// phaseArchive is the job phase packaging a generated project, after the generator phases
+const phaseArchive = "archive"
+
+// buildArchive returns the archive of a project in a format, from the archive cache when an identical
+// configuration was built before. At most maxArchiveBuilds archives are built at the same time, others
+// wait for a slot. It reports the generator phases and packaging when progress is not nil, and stops
+// generating once ctx is done.
+// Errors are generation_failed or archive_failed API errors.
+func buildArchive(ctx context.Context, config generator.ProjectConfig, format archiveFormat, progress jobs.Progress) (archive *projectArchive, cached bool, err error) {
+	// Packaging is one step more than the generator phases
+	steps := 1
+	report := func(phase string, step int) {
+		if progress != nil {
+			progress(types.JobProgress{Phase: phase, Step: step, Steps: steps})
+		}
+	}
+	config.Progress = func(event generator.ProgressEvent) {
+		steps = event.Steps + 1
+		report(event.Phase, event.Step)
+	}
 
-	// Generate project
-	config := generator.ProjectConfig{
-		Name:       req.Name,
-		ModulePath: req.ModulePath,
-		Structure:  req.Structure,
-		Database:   req.Database.Type,
-		Libraries:  req.Libraries,
-		Deployment: req.Deployment,
-		OutputDir:  projectDir,
+	// Identical configurations give identical archives, so a cached one can be served as is
+	key := archiveKey(config, format.Name)
+	if archive, ok := archives.Get(key); ok {
+		logger.Info("Serving cached archive", logger.String("project", config.Name), logger.String("sha256", archive.SHA256))
+		if Metrics != nil {
+			Metrics.IncrementCounter("projects_generated_total", map[string]string{"status": "cached"})
+		}
+		report(phaseArchive, steps)
+		return archive, true, nil
+	}
+
+	// Builds hold their project and archive in memory, so a bounded number runs at the same time
+	select {
+	case archiveBuilds <- struct{}{}:
+		defer func() { <-archiveBuilds }()
+	case <-ctx.Done():
+		return nil, false, types.APIError{
+			Code:    types.CodeGenerationFailed,
+			Message: fmt.Sprintf("Generation canceled while waiting for other builds: %v", ctx.Err()),
+		}
 	}
 
-	if err := generator.GenerateProject(&config); err != nil {
-		logger.Error("Failed to generate project", logger.Err(err), logger.String("project", req.Name))
+	// Generate project in memory, so failures are still answered with an error
+	project := generator.NewMemOutput()
+	config.Output = project
+	if err := generator.GenerateProject(ctx, &config); err != nil {
+		logger.Error("Failed to generate project", logger.Err(err), logger.String("project", config.Name))
 		if Metrics != nil {
 			Metrics.IncrementCounter("projects_generated_total", map[string]string{"status": "failed"})
 		}
-		c.JSON(500, types.GenerateResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to generate project: %v", err),
-		})
-		return
+		return nil, false, generationError(err)
 	}
 
-	logger.Info("Project generated successfully", logger.String("project", req.Name))
-
-	// Create ZIP file
-	zipFileName := fmt.Sprintf("%s.zip", req.Name)
-	zipFilePath := filepath.Join(tempDir, zipFileName)
+	logger.Info("Project generated successfully", logger.String("project", config.Name))
 
-	logger.Debug("Creating ZIP file", logger.String("path", zipFilePath))
-	if err := createZip(projectDir, zipFilePath); err != nil {
-		logger.Error("Failed to create ZIP", logger.Err(err))
+	report(phaseArchive, steps-1)
+	archive, err = newArchive(project, format, config.Name)
+	if err != nil {
+		logger.Error("Failed to create archive", logger.Err(err), logger.String("format", format.Name))
 		if Metrics != nil {
 			Metrics.IncrementCounter("projects_generated_total", map[string]string{"status": "failed"})
 		}
-		c.JSON(500, types.GenerateResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to create ZIP: %v", err),
-		})
-		return
+		return nil, false, types.APIError{
+			Code:    types.CodeArchiveFailed,
+			Message: fmt.Sprintf("Failed to create %s archive: %v", format.Name, err),
+		}
 	}
+	archives.Add(key, archive)
 
-	logger.Info("ZIP file created successfully", logger.String("file", zipFileName))
+	logger.Info("Archive created successfully", logger.String("file", archive.FileName), logger.String("sha256", archive.SHA256))
 
 	if Metrics != nil {
 		Metrics.IncrementCounter("projects_generated_total", map[string]string{"status": "success"})
 	}
-
-	// Send ZIP file
-	c.Header("Content-Type", "application/zip")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", zipFileName))
-	c.File(zipFilePath)
+	report(phaseArchive, steps)
+	return archive, false, nil
 }
 
-// createZip creates a ZIP archive of the specified directory
-func createZip(sourceDir, targetZip string) error {
-	zipFile, err := os.Create(targetZip)
-	if err != nil {
-		return err
+// generationError describes a failed generation, with a detail naming the phase it failed in
+func generationError(err error) types.APIError {
+	apiErr := types.APIError{
+		Code:    types.CodeGenerationFailed,
+		Message: fmt.Sprintf("Failed to generate project: %v", err),
 	}
-	defer zipFile.Close()
-
-	archive := zip.NewWriter(zipFile)
-	defer archive.Close()
 
-	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Get relative path
-		relPath, err := filepath.Rel(filepath.Dir(sourceDir), path)
-		if err != nil {
-			return err
-		}
-
-		// Skip the root directory itself
-		if path == sourceDir {
-			return nil
-		}
-
-		// Create header
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
+	var phaseErr *generator.PhaseError
+	if errors.As(err, &phaseErr) {
+		apiErr.Details = []types.ErrorDetail{{
+			Code:    types.CodeGenerationFailed,
+			Message: phaseErr.Err.Error(),
+			Params:  map[string]string{"phase": phaseErr.Phase},
+		}}
+	}
+	return apiErr
+}
 
-		header.Name = filepath.ToSlash(relPath)
-		header.Method = zip.Deflate
+// serveArchive sends an archive with its SHA-256 as ETag and X-Archive-SHA256.
+// X-Cache tells whether it came from the archive cache.
+func serveArchive(c *gin.Context, archive *projectArchive, cacheStatus string) {
+	c.Header("ETag", `"`+archive.SHA256+`"`)
+	c.Header("X-Archive-SHA256", archive.SHA256)
+	c.Header("X-Cache", cacheStatus)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": archive.FileName}))
+	c.Data(200, archive.Format.ContentType, archive.Data)
+}
 
-		if info.IsDir() {
-			header.Name += "/"
-		}
+// projectConfig validates a generate request against the catalog and returns its project config
+// without an output. Invalid requests are answered with an error and ok is false.
+func projectConfig(c *gin.Context, req *types.GenerateRequest) (config generator.ProjectConfig, ok bool) {
+	manifest := libraryManifest()
+	if details := validateGenerateRequest(req, manifest); len(details) > 0 {
+		logger.Warn("Invalid project configuration", logger.Int("errors", len(details)))
+		respondError(c, 400, detailsError(types.CodeValidationFailed, details))
+		return config, false
+	}
 
-		// Write header
-		writer, err := archive.CreateHeader(header)
-		if err != nil {
-			return err
-		}
+	libraries, violations := manifest.Resolve(req.Libraries, req.Database.Type, req.AddImplied)
+	if len(violations) > 0 {
+		logger.Warn("Library selection violates catalog rules", logger.Int("violations", len(violations)))
+		respondError(c, 400, detailsError(types.CodeRuleViolation, violationDetails(violations, req.Libraries)))
+		return config, false
+	}
+	req.Libraries = libraries
 
-		// If not a directory, write file content
-		if !info.IsDir() {
-			file, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer file.Close()
-
-			_, err = io.Copy(writer, file)
-			if err != nil {
-				return err
-			}
-		}
+	versions, paths := resolveLibraries(c.Request.Context(), *req)
+	config = generator.ProjectConfig{
+		Name:       req.Name,
+		ModulePath: req.ModulePath,
+		Structure:  req.Structure,
+		Database:   req.Database.Type,
+		Libraries:  req.Libraries,
+		Versions:   versions,
+		Paths:      paths,
+		Deployment: req.Deployment,
+		Verify:     req.Verify,
+	}
+	if req.ResolveDependencies {
+		config.ModuleProxy = generator.DefaultModuleProxy()
+	}
+	return config, true
+}
 
-		return nil
-	})
+// resolveLibraries returns the library versions and module paths written to go.mod:
+// the versions shown by the catalog with the request overrides applied, and the catalog module paths
+func resolveLibraries(ctx context.Context, req types.GenerateRequest) (versions, paths map[string]string) {
+	versions = make(map[string]string)
+	paths = make(map[string]string)
+	for _, lib := range availableLibraries(ctx, req.Prerelease) {
+		versions[lib.Name] = lib.Version
+		paths[lib.Name] = lib.ModulePath
+	}
+	for lib, version := range req.Versions {
+		versions[lib] = version
+	}
+	return versions, paths
 }
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/generator"
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestGenerateProject(t *testing.T) {
+	body := `{
+		"name": "demo-api",
+		"modulePath": "github.com/example/demo-api",
+		"structure": "standard",
+		"libraries": ["go-logger"]
+	}`
+
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/api/generate", strings.NewReader(body)))
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename=demo-api.zip` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	for _, want := range []string{"demo-api/go.mod", "demo-api/cmd/server/main.go", "demo-api/internal/models/"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("archive has no %s: %v", want, names)
+		}
+	}
+
+	if _, err := os.Stat("temp"); !os.IsNotExist(err) {
+		t.Errorf("generate left a temp directory: %v", err)
+	}
+}
+
+func TestGenerateProjectFormats(t *testing.T) {
+	body := `{"name": "demo-api", "modulePath": "github.com/example/demo-api"%s}`
+	tests := []struct {
+		name        string
+		format      string
+		accept      string
+		contentType string
+		file        string
+	}{
+		{"default", "", "", "application/zip", "demo-api.zip"},
+		{"any", "", "application/json, */*", "application/zip", "demo-api.zip"},
+		{"unacceptable", "", "text/html", "application/zip", "demo-api.zip"},
+		{"option", "tar.gz", "application/zip", "application/gzip", "demo-api.tar.gz"},
+		{"accept gzip", "", "application/gzip", "application/gzip", "demo-api.tar.gz"},
+		{"accept zstd", "", "application/zstd", "application/zstd", "demo-api.tar.zst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := ""
+			if tt.format != "" {
+				format = fmt.Sprintf(`, "format": %q`, tt.format)
+			}
+			req := httptest.NewRequest("POST", "/api/generate", strings.NewReader(fmt.Sprintf(body, format)))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			newTestRouter().ServeHTTP(rec, req)
+
+			if rec.Code != 200 {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != "attachment; filename="+tt.file {
+				t.Errorf("Content-Disposition = %q, want file %s", got, tt.file)
+			}
+
+			if tt.contentType == "application/zip" {
+				return
+			}
+			modes := readTar(t, tt.contentType, rec.Body.Bytes())
+			if modes["demo-api/config/"] != 0755 || modes["demo-api/go.mod"] != 0644 {
+				t.Errorf("modes = %v", modes)
+			}
+		})
+	}
+}
+
+// readTar returns the entry modes of a compressed tar archive
+func readTar(t *testing.T, contentType string, data []byte) map[string]int64 {
+	t.Helper()
+
+	var r io.Reader
+	switch contentType {
+	case "application/gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+		r = gz
+	case "application/zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("zstd: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	modes := make(map[string]int64)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return modes
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		modes[header.Name] = header.Mode
+	}
+}
+
+func TestGenerateProjectReproducible(t *testing.T) {
+	defer func(cache *archiveCache) { archives = cache }(archives)
+
+	generate := func(format string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := fmt.Sprintf(`{"name": "demo-api", "modulePath": "github.com/example/demo-api", "libraries": ["go-logger"], "format": %q}`, format)
+		rec := httptest.NewRecorder()
+		newTestRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/api/generate", strings.NewReader(body)))
+		if rec.Code != 200 {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	for _, format := range formatNames() {
+		t.Run(format, func(t *testing.T) {
+			// Without the cache, both archives are generated
+			archives = newArchiveCache(0)
+			first, second := generate(format), generate(format)
+			if !bytes.Equal(first.Body.Bytes(), second.Body.Bytes()) {
+				t.Fatal("identical requests gave different archives")
+			}
+
+			sum := sha256.Sum256(first.Body.Bytes())
+			if got, want := first.Header().Get("X-Archive-SHA256"), hex.EncodeToString(sum[:]); got != want {
+				t.Errorf("X-Archive-SHA256 = %s, want %s", got, want)
+			}
+			if got, want := first.Header().Get("ETag"), `"`+hex.EncodeToString(sum[:])+`"`; got != want {
+				t.Errorf("ETag = %s, want %s", got, want)
+			}
+
+			archives = newArchiveCache(defaultArchiveCacheSize)
+			miss, hit := generate(format), generate(format)
+			if miss.Header().Get("X-Cache") != "MISS" || hit.Header().Get("X-Cache") != "HIT" {
+				t.Errorf("X-Cache = %s then %s, want MISS then HIT", miss.Header().Get("X-Cache"), hit.Header().Get("X-Cache"))
+			}
+			if !bytes.Equal(hit.Body.Bytes(), first.Body.Bytes()) || hit.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+				t.Error("cached archive differs from the generated one")
+			}
+		})
+	}
+}
+
+func TestArchiveTooLarge(t *testing.T) {
+	project := generator.NewMemOutput()
+	if err := project.WriteFile("main.go", bytes.Repeat([]byte("x"), 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTar(&limitedWriter{w: &buf, n: 1024}, project, "demo-api"); !errors.Is(err, errArchiveTooLarge) {
+		t.Errorf("writeTar error = %v, want %v", err, errArchiveTooLarge)
+	}
+	if buf.Len() > 1024 {
+		t.Errorf("wrote %d bytes over the limit", buf.Len())
+	}
+}
+
+func TestBuildArchiveWaitsForSlot(t *testing.T) {
+	for range maxArchiveBuilds {
+		archiveBuilds <- struct{}{}
+	}
+	defer func() {
+		for range maxArchiveBuilds {
+			<-archiveBuilds
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	config := generator.ProjectConfig{Name: "busy-api", ModulePath: "github.com/example/busy-api"}
+	_, _, err := buildArchive(ctx, config, archiveFormats[0], nil)
+	var apiErr types.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != types.CodeGenerationFailed || !strings.Contains(apiErr.Message, "waiting for other builds") {
+		t.Errorf("buildArchive error = %v, want a canceled wait", err)
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/generator"
+	"github.com/OkanUysal/go-starter-api/jobs"
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// eventsKeepAlive is how often an idle event stream gets a comment
+const eventsKeepAlive = 15 * time.Second
+
+var Jobs *jobs.Queue
+
+func SetJobs(q *jobs.Queue) {
+	Jobs = q
+}
+
+// defaultJobs is the queue used when none is set, with the default options
+var defaultJobs = sync.OnceValue(func() *jobs.Queue {
+	q := jobs.New(jobs.Options{})
+	q.Start(context.Background())
+	return q
+})
+
+// jobQueue returns the job queue, or the default one when no queue is set
+func jobQueue() *jobs.Queue {
+	if Jobs == nil {
+		return defaultJobs()
+	}
+	return Jobs
+}
+
+// CreateJob queues the generation of a project
+// @Summary      Queue a project generation
+// @Description  Validates the configuration like /generate and queues its generation. Poll the returned job
+// @Description  for its status and download its archive from the artifact URL once it succeeded.
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.GenerateRequest  true  "Project configuration"
+// @Success      202      {object}  types.JobResponse
+// @Header       202      {string}  Location               "URL of the job"
+// @Failure      400      {object}  types.ErrorResponse  "invalid_body, validation_failed or rule_violation, with details per field"
+// @Failure      503      {object}  types.ErrorResponse  "queue_full or shutting_down"
+// @Router       /jobs [post]
+func CreateJob(c *gin.Context) {
+	var req types.GenerateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Invalid request body", logger.Err(err))
+		respondError(c, 400, bodyError(err))
+		return
+	}
+
+	config, ok := projectConfig(c, &req)
+	if !ok {
+		return
+	}
+
+	job, err := jobQueue().Submit(c.Request.Context(), generateTask(config, negotiateFormat(c, req.Format)))
+	switch {
+	case errors.Is(err, jobs.ErrQueueFull):
+		logger.Warn("Job queue is full", logger.String("project", req.Name))
+		respondError(c, 503, types.APIError{Code: types.CodeQueueFull, Message: "Too many queued generations, retry later"})
+		return
+	case errors.Is(err, jobs.ErrQueueClosed):
+		respondError(c, 503, shuttingDownError)
+		return
+	case err != nil:
+		logger.Error("Failed to queue job", logger.Err(err))
+		respondError(c, 500, types.APIError{Code: types.CodeInternal, Message: "Internal server error"})
+		return
+	}
+
+	logger.Info("Generation queued", logger.String("job", job.ID), logger.String("project", req.Name))
+
+	c.Header("Location", jobURL(job.ID))
+	c.JSON(202, types.JobResponse{Success: true, Data: jobView(job)})
+}
+
+// GetJob returns the status of a job
+// @Summary      Get a generation job
+// @Description  Returns the status, progress and error of a job, and its artifact once it succeeded.
+// @Description  Finished jobs are kept for an hour by default.
+// @Tags         Jobs
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  types.JobResponse
+// @Failure      404  {object}  types.ErrorResponse  "not_found"
+// @Router       /jobs/{id} [get]
+func GetJob(c *gin.Context) {
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+	c.JSON(200, types.JobResponse{Success: true, Data: jobView(job)})
+}
+
+// GetJobArtifact downloads the archive of a succeeded job
+// @Summary      Download the archive of a job
+// @Description  Returns the archive of a succeeded job, with its SHA-256 as ETag and X-Archive-SHA256
+// @Tags         Jobs
+// @Produce      application/zip,application/gzip,application/zstd
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {file}    binary  "Archive download"
+// @Failure      404  {object}  types.ErrorResponse  "not_found"
+// @Failure      409  {object}  types.ErrorResponse  "job_pending or job_failed"
+// @Router       /jobs/{id}/artifact [get]
+func GetJobArtifact(c *gin.Context) {
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+
+	switch job.Status {
+	case jobs.StatusQueued, jobs.StatusRunning:
+		respondError(c, 409, types.APIError{Code: types.CodeJobPending, Message: fmt.Sprintf("Job %s is %s", job.ID, job.Status)})
+		return
+	case jobs.StatusFailed:
+		respondError(c, 409, types.APIError{Code: types.CodeJobFailed, Message: fmt.Sprintf("Job %s failed: %s", job.ID, job.Error.Message)})
+		return
+	}
+
+	artifact, err := jobQueue().Artifact(c.Request.Context(), job.ID)
+	if err != nil {
+		logger.Error("Failed to load job artifact", logger.String("job", job.ID), logger.Err(err))
+		respondError(c, 500, types.APIError{Code: types.CodeInternal, Message: "Internal server error"})
+		return
+	}
+
+	c.Header("ETag", `"`+artifact.SHA256+`"`)
+	c.Header("X-Archive-SHA256", artifact.SHA256)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.FileName}))
+	c.Data(200, artifact.ContentType, artifact.Data)
+}
+
+// JobEvents streams the progress of a job as Server-Sent Events
+// @Summary      Follow a generation job
+// @Description  Streams the job as Server-Sent Events: one event with its current state, then one per update until it finished.
+// @Description  The event name is the job status (queued, running, succeeded or failed) and the data is the job as JSON;
+// @Description  running events carry the generator phase in progress, failed events the phase that failed.
+// @Tags         Jobs
+// @Produce      text/event-stream
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  types.Job            "One event per update"
+// @Failure      404  {object}  types.ErrorResponse  "not_found"
+// @Router       /jobs/{id}/events [get]
+func JobEvents(c *gin.Context) {
+	// Subscribe before reading the job, so no update is missed in between
+	updates, cancel := jobQueue().Subscribe(c.Param("id"))
+	defer cancel()
+	job, ok := findJob(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Keep reverse proxies from buffering the stream
+
+	// The stream lasts as long as the job, not the write timeout of the server
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	send := func(job types.Job) (finished bool) {
+		c.SSEvent(job.Status, jobView(job))
+		c.Writer.Flush()
+		return job.FinishedAt != nil
+	}
+	if send(job) {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			// A comment line, ignored by EventSource, keeps idle connections open
+			io.WriteString(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		case job, ok := <-updates:
+			if !ok {
+				// The job finished, its final state may have been dropped for this slow client
+				if job, err := jobQueue().Get(c.Request.Context(), c.Param("id")); err == nil {
+					send(job)
+				}
+				return
+			}
+			if send(job) {
+				return
+			}
+		}
+	}
+}
+
+// findJob returns the job of the id parameter. Unknown jobs are answered with 404 and ok is false.
+func findJob(c *gin.Context) (job types.Job, ok bool) {
+	job, err := jobQueue().Get(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		respondError(c, 404, types.APIError{Code: types.CodeNotFound, Message: fmt.Sprintf("No job %q", c.Param("id"))})
+		return job, false
+	case err != nil:
+		logger.Error("Failed to load job", logger.String("job", c.Param("id")), logger.Err(err))
+		respondError(c, 500, types.APIError{Code: types.CodeInternal, Message: "Internal server error"})
+		return job, false
+	}
+	return job, true
+}
+
+// generateTask returns the task building the archive of a project
+func generateTask(config generator.ProjectConfig, format archiveFormat) jobs.Task {
+	return func(ctx context.Context, progress jobs.Progress) (*jobs.Artifact, error) {
+		archive, _, err := buildArchive(ctx, config, format, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &jobs.Artifact{
+			Data:        archive.Data,
+			FileName:    archive.FileName,
+			ContentType: archive.Format.ContentType,
+			SHA256:      archive.SHA256,
+		}, nil
+	}
+}
+
+// jobView returns a job with the download URL of its artifact
+func jobView(job types.Job) types.Job {
+	if job.Artifact != nil {
+		artifact := *job.Artifact
+		artifact.URL = jobURL(job.ID) + "/artifact"
+		job.Artifact = &artifact
+	}
+	return job
+}
+
+// jobURL returns the API URL of a job
+func jobURL(id string) string {
+	return "/api/jobs/" + id
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/generator"
+	"github.com/OkanUysal/go-starter-api/jobs"
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+func TestJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer SetJobs(Jobs)
+	queue := jobs.New(jobs.Options{Workers: 1})
+	queue.Start(ctx)
+	SetJobs(queue)
+
+	router := newTestRouter()
+	get := func(path string) (*httptest.ResponseRecorder, types.JobResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		var resp types.JobResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	body := `{"name": "demo-api", "modulePath": "github.com/example/demo-api", "format": "tar.gz"}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/jobs", strings.NewReader(body)))
+	if rec.Code != 202 {
+		t.Fatalf("POST /api/jobs = %d: %s", rec.Code, rec.Body)
+	}
+	var created types.JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	location := rec.Header().Get("Location")
+	if location != "/api/jobs/"+created.Data.ID || created.Data.Status != jobs.StatusQueued {
+		t.Fatalf("created job %+v at %q", created.Data, location)
+	}
+
+	var job types.Job
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		_, resp := get(location)
+		if job = resp.Data; job.Status == jobs.StatusSucceeded || job.Status == jobs.StatusFailed {
+			break
+		}
+	}
+	if job.Status != jobs.StatusSucceeded || job.Artifact == nil || job.Progress.Step != job.Progress.Steps {
+		t.Fatalf("job = %+v", job)
+	}
+
+	rec, _ = get(job.Artifact.URL)
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != "application/gzip" || rec.Header().Get("X-Archive-SHA256") != job.Artifact.SHA256 {
+		t.Errorf("GET artifact = %d %v", rec.Code, rec.Header())
+	}
+	if modes := readTar(t, "application/gzip", rec.Body.Bytes()); modes["demo-api/go.mod"] != 0644 {
+		t.Errorf("artifact entries = %v", modes)
+	}
+
+	for path, code := range map[string]string{
+		"/api/jobs/unknown":          types.CodeNotFound,
+		"/api/jobs/unknown/artifact": types.CodeNotFound,
+	} {
+		rec, _ := get(path)
+		var resp types.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != 404 || resp.Error.Code != code {
+			t.Errorf("GET %s = %d %+v", path, rec.Code, resp.Error)
+		}
+	}
+}
+
+func TestJobArtifactPending(t *testing.T) {
+	defer SetJobs(Jobs)
+	queue := jobs.New(jobs.Options{}) // not started, jobs stay queued
+	SetJobs(queue)
+
+	job, err := queue.Submit(context.Background(), generateTask(generator.ProjectConfig{Name: "demo-api", ModulePath: "github.com/example/demo-api"}, archiveFormats[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/api/jobs/"+job.ID+"/artifact", nil))
+	var resp types.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != 409 || resp.Error.Code != types.CodeJobPending {
+		t.Errorf("GET artifact of a queued job = %d %+v", rec.Code, resp.Error)
+	}
+}
+
+// sseEvent is an event read from a Server-Sent Events stream
+type sseEvent struct {
+	Name string
+	Job  types.Job
+}
+
+// readEvent reads the next event of a stream
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.Name != "":
+			return event
+		case strings.HasPrefix(line, "event:"):
+			event.Name = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event.Job); err != nil {
+				t.Fatalf("decode event data %q: %v", line, err)
+			}
+		}
+	}
+}
+
+func TestJobEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		config generator.ProjectConfig
+		last   string
+	}{
+		{"succeeded", generator.ProjectConfig{Name: "demo-api", ModulePath: "github.com/example/demo-api"}, jobs.StatusSucceeded},
+		{"failed", generator.ProjectConfig{Name: "demo-api", ModulePath: "not a module", Structure: "standard", Verify: true}, jobs.StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// An empty cache, so every run generates the project phase by phase
+			defer func(cache *archiveCache) { archives = cache }(archives)
+			archives = newArchiveCache(defaultArchiveCacheSize)
+			defer SetJobs(Jobs)
+			queue := jobs.New(jobs.Options{Workers: 1}) // started once the stream is open
+			SetJobs(queue)
+			server := httptest.NewServer(newTestRouter())
+			defer server.Close()
+
+			job, err := queue.Submit(ctx, generateTask(tt.config, archiveFormats[0]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.Get(server.URL + "/api/jobs/" + job.ID + "/events")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+				t.Fatalf("GET events = %d %v", resp.StatusCode, resp.Header)
+			}
+
+			body := bufio.NewReader(resp.Body)
+			if event := readEvent(t, body); event.Name != jobs.StatusQueued {
+				t.Fatalf("first event = %+v, want queued", event)
+			}
+			queue.Start(ctx)
+
+			var phases []string
+			for {
+				event := readEvent(t, body)
+				if event.Name != jobs.StatusRunning {
+					if event.Name != tt.last || event.Job.FinishedAt == nil {
+						t.Fatalf("last event = %+v, want %s", event, tt.last)
+					}
+					if tt.last == jobs.StatusFailed {
+						if detail := event.Job.Error.Details[0]; detail.Params["phase"] != generator.PhaseVerify {
+							t.Errorf("failure detail = %+v, want phase %s", detail, generator.PhaseVerify)
+						}
+					} else if event.Job.Artifact == nil || event.Job.Artifact.URL == "" {
+						t.Errorf("succeeded job = %+v", event.Job)
+					}
+					break
+				}
+				if phase := event.Job.Progress.Phase; phase != "" && !slices.Contains(phases, phase) {
+					phases = append(phases, phase)
+				}
+			}
+			if len(phases) == 0 || phases[0] != generator.PhaseStructure {
+				t.Errorf("running phases = %v", phases)
+			}
+			if _, err := body.ReadByte(); err != io.EOF {
+				t.Errorf("stream not closed after the last event: %v", err)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/api/jobs/unknown/events", nil))
+	if rec.Code != 404 {
+		t.Errorf("GET events of an unknown job = %d", rec.Code)
+	}
+}
+
+func TestCreateJobShuttingDown(t *testing.T) {
+	defer SetJobs(Jobs)
+	queue := jobs.New(jobs.Options{})
+	queue.Shutdown(context.Background())
+	SetJobs(queue)
+
+	body := `{"name": "demo-api", "modulePath": "github.com/example/demo-api"}`
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/api/jobs", strings.NewReader(body)))
+	var resp types.ErrorResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != 503 || resp.Error.Code != types.CodeShuttingDown {
+		t.Errorf("POST /api/jobs after shutdown = %d %+v", rec.Code, resp.Error)
+	}
+}
+
+func TestRefuseWhenDraining(t *testing.T) {
+	Drain()
+	defer draining.Store(false)
+
+	body := `{"name": "demo-api", "modulePath": "github.com/example/demo-api"}`
+	for _, path := range []string{"/api/generate", "/api/preview", "/api/jobs"} {
+		rec := httptest.NewRecorder()
+		newTestRouter().ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		var resp types.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != 503 || resp.Error.Code != types.CodeShuttingDown {
+			t.Errorf("POST %s while draining = %d %+v", path, rec.Code, resp.Error)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/api/libraries", nil))
+	if rec.Code != 200 {
+		t.Errorf("GET /api/libraries while draining = %d", rec.Code)
+	}
+}
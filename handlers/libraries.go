@@ -1,18 +1,28 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
 	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/catalog"
 	"github.com/OkanUysal/go-starter-api/types"
 	"github.com/gin-gonic/gin"
 )
 
 // GetLibraries returns all available libraries
 // @Summary      Get all available libraries
-// @Description  Returns a list of all 10 production Go libraries with their metadata
+// @Description  Returns the built-in production Go libraries and those of registered catalog sources with their metadata
 // @Tags         Libraries
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  map[string]interface{}  "success, data (array of Library), count"
+// @Param        prerelease  query  bool    false  "Use the latest pre-release as version when it is newer than the latest stable tag"
+// @Param        source      query  string  false  "Only return the libraries of this catalog source (e.g. builtin)"
+// @Success      200  {object}  types.LibrariesResponse
+// @Failure      400  {object}  types.ErrorResponse  "invalid_query"
 // @Router       /libraries [get]
 func GetLibraries(c *gin.Context) {
 	logger.Debug("Fetching available libraries")
@@ -21,12 +31,54 @@ func GetLibraries(c *gin.Context) {
 		Metrics.IncrementCounter("libraries_requested_total", nil)
 	}
 
-	libraries := types.GetAvailableLibraries()
+	var prerelease bool
+	if value := c.Query("prerelease"); value != "" {
+		var err error
+		if prerelease, err = strconv.ParseBool(value); err != nil {
+			respondError(c, 400, types.APIError{
+				Code:    types.CodeInvalidQuery,
+				Message: fmt.Sprintf("prerelease must be true or false, not %q", value),
+				Field:   "prerelease",
+			})
+			return
+		}
+	}
+
+	libraries := availableLibraries(c.Request.Context(), prerelease)
+
+	// Sources of all libraries, so clients can offer the filter
+	var sources []string
+	for _, lib := range libraries {
+		if !slices.Contains(sources, lib.Source) {
+			sources = append(sources, lib.Source)
+		}
+	}
+
+	if source := c.Query("source"); source != "" {
+		if !slices.Contains(sources, source) {
+			respondError(c, 400, types.APIError{
+				Code:    types.CodeInvalidQuery,
+				Message: fmt.Sprintf("Unknown source %q, use %s", source, strings.Join(sources, ", ")),
+				Field:   "source",
+			})
+			return
+		}
+		libraries = slices.DeleteFunc(libraries, func(lib types.Library) bool { return lib.Source != source })
+	}
 
 	logger.Info("Libraries retrieved", logger.Int("count", len(libraries)))
-	c.JSON(200, gin.H{
-		"success": true,
-		"data":    libraries,
-		"count":   len(libraries),
+	c.JSON(200, types.LibrariesResponse{
+		Success: true,
+		Data:    libraries,
+		Count:   len(libraries),
+		Sources: sources,
 	})
 }
+
+// availableLibraries returns the libraries from the catalog, or the built-in manifest when no catalog is set
+func availableLibraries(ctx context.Context, includePrerelease bool) []types.Library {
+	if Catalog == nil {
+		return append([]types.Library(nil), catalog.DefaultManifest().Libraries...)
+	}
+	return Catalog.Libraries(ctx, includePrerelease)
+}
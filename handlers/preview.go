@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/generator"
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewProject generates a project and returns its files as JSON
+// @Summary      Preview a generated Go project
+// @Description  Generates a project like /generate and returns its file tree with sizes and, with includeContents, the content of every file
+// @Tags         Generator
+// @Accept       json
+// @Produce      json
+// @Param        request  body      types.PreviewRequest   true  "Project configuration"
+// @Success      200      {object}  types.PreviewResponse
+// @Failure      400      {object}  types.ErrorResponse  "invalid_body, validation_failed or rule_violation, with details per field"
+// @Failure      500      {object}  types.ErrorResponse  "generation_failed"
+// @Failure      503      {object}  types.ErrorResponse  "shutting_down"
+// @Router       /preview [post]
+func PreviewProject(c *gin.Context) {
+	var req types.PreviewRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Invalid request body", logger.Err(err))
+		respondError(c, 400, bodyError(err))
+		return
+	}
+
+	logger.Debug("Previewing project", logger.String("name", req.Name))
+
+	config, ok := projectConfig(c, &req.GenerateRequest)
+	if !ok {
+		return
+	}
+
+	project := generator.NewMemOutput()
+	config.Output = project
+	if err := generator.GenerateProject(c.Request.Context(), &config); err != nil {
+		logger.Error("Failed to generate preview", logger.Err(err), logger.String("project", req.Name))
+		respondError(c, 500, generationError(err))
+		return
+	}
+
+	resp, err := previewFiles(project, req.IncludeContents)
+	if err != nil {
+		logger.Error("Failed to read preview", logger.Err(err))
+		respondError(c, 500, types.APIError{
+			Code:    types.CodeGenerationFailed,
+			Message: fmt.Sprintf("Failed to read generated project: %v", err),
+		})
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
+// previewFiles lists the files and directories of a generated project in path order
+func previewFiles(project fs.FS, includeContents bool) (types.PreviewResponse, error) {
+	resp := types.PreviewResponse{Success: true, Files: []types.PreviewFile{}}
+
+	err := fs.WalkDir(project, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == "." {
+			return err
+		}
+		if d.IsDir() {
+			resp.Files = append(resp.Files, types.PreviewFile{Path: path, Dir: true})
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		file := types.PreviewFile{Path: path, Size: info.Size()}
+		if includeContents {
+			content, err := fs.ReadFile(project, path)
+			if err != nil {
+				return err
+			}
+			file.Content = string(content)
+		}
+
+		resp.Files = append(resp.Files, file)
+		resp.Count++
+		resp.Size += file.Size
+		return nil
+	})
+	return resp, err
+}
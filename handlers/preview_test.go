@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+func TestPreviewProject(t *testing.T) {
+	body := `{
+		"name": "demo-api",
+		"modulePath": "github.com/example/demo-api",
+		"structure": "standard",
+		"database": {"type": "postgres"},
+		"libraries": ["go-auth", "go-migration"],
+		"includeContents": true
+	}`
+
+	rec := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/api/preview", strings.NewReader(body)))
+	if rec.Code != 200 {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp types.PreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	files := make(map[string]types.PreviewFile)
+	var size int64
+	for _, f := range resp.Files {
+		files[f.Path] = f
+		size += f.Size
+	}
+
+	if f := files["go.mod"]; !strings.HasPrefix(f.Content, "module github.com/example/demo-api\n") || f.Size != int64(len(f.Content)) {
+		t.Errorf("go.mod = %+v", f)
+	}
+	if f := files["internal/middleware/auth.go"]; f.Content == "" {
+		t.Error("auth middleware missing from preview")
+	}
+	if f, ok := files["migrations"]; !ok || !f.Dir {
+		t.Error("migrations directory missing from preview")
+	}
+	if resp.Size != size || resp.Count == 0 || resp.Count >= len(resp.Files) {
+		t.Errorf("count %d, size %d for %d entries of %d bytes", resp.Count, resp.Size, len(resp.Files), size)
+	}
+
+	// Without includeContents only paths and sizes are returned
+	body = strings.Replace(body, `"includeContents": true`, `"includeContents": false`, 1)
+	rec = httptest.NewRecorder()
+	newTestRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/api/preview", strings.NewReader(body)))
+	if strings.Contains(rec.Body.String(), `"content"`) {
+		t.Error("contents returned without includeContents")
+	}
+}
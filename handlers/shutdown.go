@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"sync/atomic"
+
+	"github.com/OkanUysal/go-starter-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// draining is set once the server shuts down
+var draining atomic.Bool
+
+// shuttingDownError refuses work while the server shuts down
+var shuttingDownError = types.APIError{Code: types.CodeShuttingDown, Message: "Server is shutting down, retry later"}
+
+// Drain makes RefuseWhenDraining refuse new generations, when the server starts shutting down
+func Drain() {
+	draining.Store(true)
+}
+
+// RefuseWhenDraining answers 503 shutting_down once Drain was called, so running generations and jobs
+// can finish without new work arriving; use it before the handlers that generate projects
+func RefuseWhenDraining(c *gin.Context) {
+	if draining.Load() {
+		respondError(c, 503, shuttingDownError)
+	}
+}
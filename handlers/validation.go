@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/OkanUysal/go-starter-api/catalog"
+	"github.com/OkanUysal/go-starter-api/types"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// namePattern matches project names. Names become a directory, the ZIP file name and the
+// Content-Disposition header, so they cannot contain path separators, dots first or quotes.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// Accepted values of the project options; "" selects the generator default
+var (
+	structures  = []string{"simple", "standard"}
+	deployments = []string{"railway", "local", "docker"}
+	databases   = append([]string{"none"}, catalog.Databases...)
+)
+
+// validateGenerateRequest trims the request fields and reports every invalid one.
+// Libraries and version overrides must name libraries of the manifest.
+func validateGenerateRequest(req *types.GenerateRequest, manifest *catalog.Manifest) []types.ErrorDetail {
+	req.Name = strings.TrimSpace(req.Name)
+	req.ModulePath = strings.TrimSpace(req.ModulePath)
+	req.Structure = strings.TrimSpace(req.Structure)
+	req.Database.Type = strings.TrimSpace(req.Database.Type)
+	req.Deployment = strings.TrimSpace(req.Deployment)
+	req.Format = strings.TrimSpace(req.Format)
+
+	var errs []types.ErrorDetail
+	fail := func(field, code string, params map[string]string, format string, args ...any) {
+		errs = append(errs, types.ErrorDetail{
+			Code:    code,
+			Message: fmt.Sprintf(format, args...),
+			Field:   field,
+			Params:  params,
+		})
+	}
+	oneOf := func(field, label, value string, allowed []string) {
+		if value != "" && !slices.Contains(allowed, value) {
+			fail(field, types.CodeUnknownValue, map[string]string{"value": value, "allowed": strings.Join(allowed, ",")},
+				"Unknown %s %q, use %s", label, value, strings.Join(allowed, ", "))
+		}
+	}
+
+	switch {
+	case req.Name == "":
+		fail("name", types.CodeRequired, nil, "Project name is required")
+	case !namePattern.MatchString(req.Name):
+		fail("name", types.CodeInvalidFormat, map[string]string{"value": req.Name},
+			"Project name must start with a letter or digit and contain only letters, digits, '-' and '_' (at most 64)")
+	}
+
+	var pathErr *module.InvalidPathError
+	switch err := module.CheckPath(req.ModulePath); {
+	case req.ModulePath == "":
+		fail("modulePath", types.CodeRequired, nil, "Module path is required")
+	case errors.As(err, &pathErr):
+		fail("modulePath", types.CodeInvalidFormat, map[string]string{"value": req.ModulePath, "reason": pathErr.Err.Error()},
+			"Invalid module path: %v", pathErr.Err)
+	case err != nil:
+		fail("modulePath", types.CodeInvalidFormat, map[string]string{"value": req.ModulePath, "reason": err.Error()},
+			"Invalid module path: %v", err)
+	}
+
+	oneOf("structure", "structure", req.Structure, structures)
+	oneOf("database.type", "database", req.Database.Type, databases)
+	oneOf("deployment", "deployment", req.Deployment, deployments)
+	oneOf("format", "format", req.Format, formatNames())
+
+	for i, lib := range req.Libraries {
+		field := fmt.Sprintf("libraries[%d]", i)
+		switch {
+		case !isLibrary(manifest, lib):
+			fail(field, types.CodeUnknownLibrary, map[string]string{"library": lib}, "Unknown library %q", lib)
+		case slices.Index(req.Libraries, lib) < i:
+			fail(field, types.CodeDuplicateLibrary, map[string]string{"library": lib}, "Library %q is listed more than once", lib)
+		}
+	}
+
+	// Sorted, so the errors do not depend on map order
+	libs := make([]string, 0, len(req.Versions))
+	for lib := range req.Versions {
+		libs = append(libs, lib)
+	}
+	sort.Strings(libs)
+	for _, lib := range libs {
+		field := "versions." + lib
+		switch version := req.Versions[lib]; {
+		case !isLibrary(manifest, lib):
+			fail(field, types.CodeUnknownLibrary, map[string]string{"library": lib}, "Unknown library %q", lib)
+		case !semver.IsValid(version):
+			fail(field, types.CodeInvalidVersion, map[string]string{"library": lib, "version": version},
+				"Invalid version %q for %s", version, lib)
+		}
+	}
+
+	return errs
+}
+
+// violationCodes are the error codes of the catalog rules
+var violationCodes = map[string]string{
+	catalog.RuleRequires:  types.CodeRequiresLibrary,
+	catalog.RuleConflicts: types.CodeConflictingLibrary,
+	catalog.RuleDatabase:  types.CodeRequiresDatabase,
+}
+
+// violationDetails turns catalog rule violations into error details on the fields at fault.
+// Libraries added because they were implied have no field.
+func violationDetails(violations []types.Violation, requested []string) []types.ErrorDetail {
+	details := make([]types.ErrorDetail, 0, len(violations))
+	for _, v := range violations {
+		detail := types.ErrorDetail{
+			Code:    violationCodes[v.Rule],
+			Message: v.Message,
+			Params:  map[string]string{"library": v.Library, "target": v.Target},
+		}
+		switch i := slices.Index(requested, v.Library); {
+		case v.Rule == catalog.RuleDatabase:
+			detail.Field = "database.type"
+		case i >= 0:
+			detail.Field = fmt.Sprintf("libraries[%d]", i)
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// isLibrary reports whether the manifest lists a library
+func isLibrary(manifest *catalog.Manifest, name string) bool {
+	_, ok := manifest.Library(name)
+	return ok
+}
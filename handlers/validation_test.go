@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/OkanUysal/go-starter-api/catalog"
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+func TestValidateGenerateRequest(t *testing.T) {
+	valid := func() types.GenerateRequest {
+		return types.GenerateRequest{
+			Name:       "demo-api",
+			ModulePath: "github.com/example/demo-api",
+			Structure:  "standard",
+			Database:   types.DatabaseConfig{Type: "postgres"},
+			Libraries:  []string{"go-logger", "go-migration"},
+			Deployment: "docker",
+			Versions:   map[string]string{"go-logger": "v1.2.0"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *types.GenerateRequest)
+		fields []string
+	}{
+		{"valid", func(*types.GenerateRequest) {}, nil},
+		{"defaults", func(req *types.GenerateRequest) {
+			req.Structure, req.Database.Type, req.Deployment, req.Libraries, req.Versions = "", "", "", nil, nil
+		}, nil},
+		{"trimmed", func(req *types.GenerateRequest) {
+			req.Name, req.ModulePath = " demo-api ", "\tgithub.com/example/demo-api\n"
+		}, nil},
+		{"missing", func(req *types.GenerateRequest) { req.Name, req.ModulePath = "", "" }, []string{"name", "modulePath"}},
+		{"path traversal", func(req *types.GenerateRequest) { req.Name = "../../etc" }, []string{"name"}},
+		{"slash", func(req *types.GenerateRequest) { req.Name = "demo/api" }, []string{"name"}},
+		{"quote", func(req *types.GenerateRequest) { req.Name = `demo"; filename=x` }, []string{"name"}},
+		{"hidden", func(req *types.GenerateRequest) { req.Name = ".env" }, []string{"name"}},
+		{"too long", func(req *types.GenerateRequest) { req.Name = strings.Repeat("a", 65) }, []string{"name"}},
+		{"module path", func(req *types.GenerateRequest) { req.ModulePath = "github.com/example/demo api" }, []string{"modulePath"}},
+		{"module path without dot", func(req *types.GenerateRequest) { req.ModulePath = "demo-api" }, []string{"modulePath"}},
+		{"options", func(req *types.GenerateRequest) {
+			req.Structure, req.Database.Type, req.Deployment, req.Format = "hexagonal", "sqlite", "heroku", "rar"
+		}, []string{"structure", "database.type", "deployment", "format"}},
+		{"libraries", func(req *types.GenerateRequest) {
+			req.Libraries = []string{"go-logger", "go-orm", "go-logger"}
+		}, []string{"libraries[1]", "libraries[2]"}},
+		{"versions", func(req *types.GenerateRequest) {
+			req.Versions = map[string]string{"go-orm": "v1.0.0", "go-auth": "latest"}
+		}, []string{"versions.go-auth", "versions.go-orm"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+
+			var fields []string
+			for _, err := range validateGenerateRequest(&req, catalog.DefaultManifest()) {
+				fields = append(fields, err.Field)
+			}
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("invalid fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
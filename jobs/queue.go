@@ -0,0 +1,333 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OkanUysal/go-logger"
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// expireInterval is how often finished jobs older than the TTL are removed
+const expireInterval = time.Minute
+
+// finishTimeout bounds storing the final state of a job, which goes on when the job was canceled
+const finishTimeout = 5 * time.Second
+
+// subscriberBuffer is how many updates a subscriber can fall behind before progress updates are dropped for it
+const subscriberBuffer = 64
+
+// ErrQueueFull is returned by Submit when QueueSize jobs are already waiting
+var ErrQueueFull = errors.New("job queue is full")
+
+// ErrQueueClosed is returned by Submit once Shutdown was called
+var ErrQueueClosed = errors.New("job queue is shut down")
+
+// Progress reports how far a task got
+type Progress func(progress types.JobProgress)
+
+// Task produces the artifact of a job. A failing task whose error is a types.APIError
+// keeps that error on the job, other errors are only logged and fail it as internal_error.
+type Task func(ctx context.Context, progress Progress) (*Artifact, error)
+
+// Options configures a job Queue
+type Options struct {
+	Workers   int           // Jobs run at the same time (default 2)
+	QueueSize int           // Jobs waiting for a worker before Submit refuses new ones (default 100)
+	TTL       time.Duration // How long finished jobs and their artifacts are kept (default 1h)
+	Store     Store         // Where jobs are kept (default a MemoryStore)
+}
+
+// Queue runs submitted tasks on a bounded pool of workers and keeps their state in a Store
+type Queue struct {
+	opts    Options
+	pending chan pendingJob
+	workers sync.WaitGroup
+
+	submitMu sync.Mutex    // serializes Submit and Shutdown: room found in pending stays free, nothing is sent after closing
+	closed   bool          // set by Shutdown
+	closing  chan struct{} // closed by Shutdown
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan types.Job]bool // by job ID
+}
+
+// pendingJob is a submitted job waiting for a worker
+type pendingJob struct {
+	id   string
+	task Task
+}
+
+// New creates a Queue; call Start to run its workers
+func New(opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = 2
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 100
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Hour
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+
+	return &Queue{
+		opts:        opts,
+		pending:     make(chan pendingJob, opts.QueueSize),
+		closing:     make(chan struct{}),
+		subscribers: make(map[string]map[chan types.Job]bool),
+	}
+}
+
+// Start runs the workers and removes expired jobs until ctx is done
+func (q *Queue) Start(ctx context.Context) {
+	q.workers.Add(q.opts.Workers)
+	for range q.opts.Workers {
+		go func() {
+			defer q.workers.Done()
+			q.work(ctx)
+		}()
+	}
+
+	go func() {
+		ticker := time.NewTicker(expireInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			deleted, err := q.opts.Store.DeleteFinished(ctx, time.Now().Add(-q.opts.TTL))
+			if err != nil {
+				logger.Warn("Failed to remove expired jobs", logger.Err(err))
+			} else if deleted > 0 {
+				logger.Debug("Removed expired jobs", logger.Int("jobs", deleted))
+			}
+		}
+	}()
+}
+
+// Submit queues a task and returns its job. It fails with ErrQueueFull instead of waiting for room,
+// and with ErrQueueClosed once the queue is shut down.
+func (q *Queue) Submit(ctx context.Context, task Task) (types.Job, error) {
+	q.submitMu.Lock()
+	defer q.submitMu.Unlock()
+	if q.closed {
+		return types.Job{}, ErrQueueClosed
+	}
+	// Workers only take from pending, so the room checked here is still free when sending
+	if len(q.pending) == cap(q.pending) {
+		return types.Job{}, ErrQueueFull
+	}
+
+	job := types.Job{ID: rand.Text(), Status: StatusQueued, CreatedAt: time.Now()}
+	if err := q.opts.Store.Put(ctx, job); err != nil {
+		return types.Job{}, err
+	}
+	q.pending <- pendingJob{id: job.ID, task: task}
+	return job, nil
+}
+
+// Get returns a job
+func (q *Queue) Get(ctx context.Context, id string) (types.Job, error) {
+	return q.opts.Store.Get(ctx, id)
+}
+
+// Artifact returns the artifact of a succeeded job
+func (q *Queue) Artifact(ctx context.Context, id string) (*Artifact, error) {
+	return q.opts.Store.Artifact(ctx, id)
+}
+
+// Subscribe returns a channel receiving the job every time this queue stores it, and a function ending
+// the subscription. Subscribe before reading the current state, so no update falls in between.
+// The channel is closed once the job finished. A subscriber too slow to receive the final state
+// gets the channel closed all the same, and reads the final state with Get.
+func (q *Queue) Subscribe(id string) (updates <-chan types.Job, cancel func()) {
+	ch := make(chan types.Job, subscriberBuffer)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.subscribers[id] == nil {
+		q.subscribers[id] = make(map[chan types.Job]bool)
+	}
+	q.subscribers[id][ch] = true
+
+	return ch, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if q.subscribers[id][ch] {
+			delete(q.subscribers[id], ch)
+			if len(q.subscribers[id]) == 0 {
+				delete(q.subscribers, id)
+			}
+			close(ch)
+		}
+	}
+}
+
+// Shutdown stops the workers from starting queued jobs and waits until the running ones finished,
+// or until ctx is done. Jobs still queued fail with shutting_down.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.submitMu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.closing)
+	}
+	q.submitMu.Unlock()
+
+	// Jobs still waiting will not run; a worker may take some of them, it abandons them as well
+drain:
+	for {
+		select {
+		case p := <-q.pending:
+			q.abandon(ctx, p)
+		default:
+			break drain
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work runs pending jobs until ctx is done or the queue shuts down
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.closing:
+			return
+		case p := <-q.pending:
+			select {
+			case <-q.closing:
+				q.abandon(ctx, p)
+			default:
+				q.run(ctx, p)
+			}
+		}
+	}
+}
+
+// abandon fails a queued job that will not run because the queue shuts down
+func (q *Queue) abandon(ctx context.Context, p pendingJob) {
+	job, err := q.opts.Store.Get(ctx, p.id)
+	if err != nil {
+		logger.Error("Failed to load job", logger.String("job", p.id), logger.Err(err))
+		return
+	}
+	q.finish(ctx, &job, nil, types.APIError{Code: types.CodeShuttingDown, Message: "Server shut down before the job started"})
+}
+
+// run runs the task of a job and stores its result
+func (q *Queue) run(ctx context.Context, p pendingJob) {
+	job, err := q.opts.Store.Get(ctx, p.id)
+	if err != nil {
+		logger.Error("Failed to load job", logger.String("job", p.id), logger.Err(err))
+		return
+	}
+
+	started := time.Now()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	q.put(ctx, job)
+
+	progress := func(progress types.JobProgress) {
+		job.Progress = progress
+		q.put(ctx, job)
+	}
+
+	artifact, err := runTask(ctx, p.task, progress)
+	q.finish(ctx, &job, artifact, err)
+}
+
+// runTask runs a task, turning a panic into an error
+func runTask(ctx context.Context, task Task, progress Progress) (artifact *Artifact, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("task panicked: %v", recovered)
+		}
+	}()
+	return task(ctx, progress)
+}
+
+// finish stores the artifact and final state of a job. They are stored even when ctx is canceled,
+// as it is for jobs canceled at shutdown.
+func (q *Queue) finish(ctx context.Context, job *types.Job, artifact *Artifact, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	if err == nil {
+		err = q.opts.Store.PutArtifact(ctx, job.ID, artifact)
+	}
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	if err != nil {
+		apiErr := types.APIError{Code: types.CodeInternal, Message: "Internal server error"}
+		errors.As(err, &apiErr)
+		job.Status = StatusFailed
+		job.Error = &apiErr
+		logger.Warn("Job failed", logger.String("job", job.ID), logger.String("code", apiErr.Code), logger.Err(err))
+	} else {
+		job.Status = StatusSucceeded
+		job.Artifact = &types.JobArtifact{
+			FileName:    artifact.FileName,
+			ContentType: artifact.ContentType,
+			Size:        int64(len(artifact.Data)),
+			SHA256:      artifact.SHA256,
+		}
+		logger.Info("Job succeeded", logger.String("job", job.ID), logger.String("file", artifact.FileName))
+	}
+	q.put(ctx, *job)
+}
+
+// put stores a job, logging failures: the job keeps running, only its state is stale
+func (q *Queue) put(ctx context.Context, job types.Job) {
+	if err := q.opts.Store.Put(ctx, job); err != nil {
+		logger.Error("Failed to store job", logger.String("job", job.ID), logger.Err(err))
+	}
+
+	finished := job.FinishedAt != nil
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for ch := range q.subscribers[job.ID] {
+		select {
+		case ch <- job:
+		default:
+			logger.Warn("Dropped job update for a slow subscriber", logger.String("job", job.ID))
+		}
+		if finished {
+			close(ch)
+		}
+	}
+	if finished {
+		delete(q.subscribers, job.ID)
+	}
+}
@@ -0,0 +1,269 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// waitJob polls a job until it finished
+func waitJob(t *testing.T, q *Queue, id string) types.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return types.Job{}
+}
+
+func TestQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(Options{Workers: 2})
+	q.Start(ctx)
+
+	tests := []struct {
+		name   string
+		task   Task
+		status string
+		code   string
+	}{
+		{"succeeded", func(_ context.Context, progress Progress) (*Artifact, error) {
+			progress(types.JobProgress{Phase: "build", Step: 1, Steps: 1})
+			return &Artifact{Data: []byte("data"), FileName: "demo.zip", ContentType: "application/zip", SHA256: "abc"}, nil
+		}, StatusSucceeded, ""},
+		{"api error", func(context.Context, Progress) (*Artifact, error) {
+			return nil, types.APIError{Code: types.CodeGenerationFailed, Message: "Failed to generate project"}
+		}, StatusFailed, types.CodeGenerationFailed},
+		{"error", func(context.Context, Progress) (*Artifact, error) {
+			return nil, errors.New("disk on fire")
+		}, StatusFailed, types.CodeInternal},
+		{"panic", func(context.Context, Progress) (*Artifact, error) {
+			panic("boom")
+		}, StatusFailed, types.CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submitted, err := q.Submit(ctx, tt.task)
+			if err != nil {
+				t.Fatalf("Submit: %v", err)
+			}
+			if submitted.Status != StatusQueued || submitted.ID == "" {
+				t.Errorf("submitted job = %+v", submitted)
+			}
+
+			job := waitJob(t, q, submitted.ID)
+			if job.Status != tt.status || job.StartedAt == nil {
+				t.Errorf("job = %+v, want status %s", job, tt.status)
+			}
+			if tt.code != "" && (job.Error == nil || job.Error.Code != tt.code) {
+				t.Errorf("error = %+v, want code %s", job.Error, tt.code)
+			}
+			if tt.code == "" && (job.Error != nil || job.Artifact == nil || job.Artifact.Size != 4 || job.Progress.Phase != "build") {
+				t.Errorf("succeeded job = %+v", job)
+			}
+
+			_, err = q.Artifact(ctx, job.ID)
+			if succeeded := tt.status == StatusSucceeded; (err == nil) != succeeded {
+				t.Errorf("Artifact error = %v", err)
+			}
+		})
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	// Not started, so nothing leaves the queue
+	store := NewMemoryStore()
+	q := New(Options{QueueSize: 1, Store: store})
+	task := func(context.Context, Progress) (*Artifact, error) { return &Artifact{}, nil }
+
+	if _, err := q.Submit(context.Background(), task); err != nil {
+		t.Fatalf("first Submit: %v", err)
+	}
+	if _, err := q.Submit(context.Background(), task); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("second Submit error = %v, want ErrQueueFull", err)
+	}
+	// The refused job is not kept: nobody knows its ID
+	if len(store.jobs) != 1 {
+		t.Errorf("store has %d jobs, want only the queued one", len(store.jobs))
+	}
+}
+
+// contextStore is a MemoryStore refusing writes with a canceled context, like a database-backed Store
+type contextStore struct {
+	*MemoryStore
+}
+
+func (s contextStore) Put(ctx context.Context, job types.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStore.Put(ctx, job)
+}
+
+func TestQueueCanceledJobIsStored(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(Options{Store: contextStore{NewMemoryStore()}})
+	q.Start(ctx)
+
+	started := make(chan bool)
+	job, err := q.Submit(context.Background(), func(ctx context.Context, _ Progress) (*Artifact, error) {
+		started <- true
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	cancel() // as shutdown does when running jobs outlast its timeout
+
+	if job := waitJob(t, q, job.ID); job.Status != StatusFailed {
+		t.Errorf("status = %s, want %s", job.Status, StatusFailed)
+	}
+}
+
+func TestMemoryStoreDeleteFinished(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Now()
+	old, recent := now.Add(-2*time.Hour), now.Add(-time.Minute)
+
+	store.Put(ctx, types.Job{ID: "old", FinishedAt: &old})
+	store.Put(ctx, types.Job{ID: "recent", FinishedAt: &recent})
+	store.Put(ctx, types.Job{ID: "running"})
+	store.PutArtifact(ctx, "old", &Artifact{})
+
+	if deleted, err := store.DeleteFinished(ctx, now.Add(-time.Hour)); err != nil || deleted != 1 {
+		t.Fatalf("DeleteFinished = %d, %v", deleted, err)
+	}
+	for id, want := range map[string]bool{"old": false, "recent": true, "running": true} {
+		if _, err := store.Get(ctx, id); (err == nil) != want {
+			t.Errorf("Get(%s) error = %v", id, err)
+		}
+	}
+	if _, err := store.Artifact(ctx, "old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("artifact of a deleted job: %v", err)
+	}
+}
+
+func TestQueueSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(Options{})
+
+	job, err := q.Submit(ctx, func(_ context.Context, progress Progress) (*Artifact, error) {
+		progress(types.JobProgress{Phase: "one", Step: 0, Steps: 2})
+		progress(types.JobProgress{Phase: "two", Step: 1, Steps: 2})
+		return &Artifact{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, unsubscribe := q.Subscribe(job.ID)
+	q.Start(ctx)
+
+	var seen []string
+	for update := range updates {
+		seen = append(seen, update.Status+":"+update.Progress.Phase)
+		if update.FinishedAt != nil {
+			unsubscribe()
+		}
+	}
+	want := []string{"running:", "running:one", "running:two", "succeeded:two"}
+	if !slices.Equal(seen, want) {
+		t.Errorf("updates = %v, want %v", seen, want)
+	}
+	unsubscribe() // a second call is a no-op
+}
+
+func TestQueueSlowSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(Options{})
+
+	job, err := q.Submit(ctx, func(_ context.Context, progress Progress) (*Artifact, error) {
+		for step := range 2 * subscriberBuffer {
+			progress(types.JobProgress{Phase: "step", Step: step, Steps: 2 * subscriberBuffer})
+		}
+		return &Artifact{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, unsubscribe := q.Subscribe(job.ID)
+	defer unsubscribe()
+	q.Start(ctx)
+	waitJob(t, q, job.ID)
+
+	// The buffer filled up and the final state was dropped, the channel is closed all the same
+	received := 0
+	for update := range updates {
+		received++
+		if update.FinishedAt != nil {
+			t.Error("final state was not dropped")
+		}
+	}
+	if received != subscriberBuffer {
+		t.Errorf("received %d updates, want %d", received, subscriberBuffer)
+	}
+}
+
+func TestQueueShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(Options{Workers: 1})
+	q.Start(ctx)
+
+	started, release := make(chan bool), make(chan bool)
+	running, err := q.Submit(ctx, func(context.Context, Progress) (*Artifact, error) {
+		started <- true
+		<-release
+		return &Artifact{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	queued, err := q.Submit(ctx, func(context.Context, Progress) (*Artifact, error) {
+		t.Error("queued job ran after shutdown")
+		return &Artifact{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The running job outlives a short shutdown, then a patient one waits for it
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelShort()
+	if err := q.Shutdown(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown with a running job = %v, want DeadlineExceeded", err)
+	}
+	if _, err := q.Submit(ctx, nil); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Submit after Shutdown = %v, want ErrQueueClosed", err)
+	}
+	close(release)
+	if err := q.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+
+	if job, _ := q.Get(ctx, running.ID); job.Status != StatusSucceeded {
+		t.Errorf("running job = %+v, want it to finish", job)
+	}
+	if job, _ := q.Get(ctx, queued.ID); job.Status != StatusFailed || job.Error.Code != types.CodeShuttingDown {
+		t.Errorf("queued job = %+v, want it failed with %s", job, types.CodeShuttingDown)
+	}
+}
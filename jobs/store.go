@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/OkanUysal/go-starter-api/types"
+)
+
+// ErrNotFound is returned for unknown or expired jobs
+var ErrNotFound = errors.New("job not found")
+
+// Artifact is the archive produced by a job
+type Artifact struct {
+	Data        []byte
+	FileName    string
+	ContentType string
+	SHA256      string
+}
+
+// Store keeps job state and artifacts. The Queue is its only writer, so implementations
+// backed by a database or cache only need to be safe for concurrent use.
+type Store interface {
+	// Put creates or replaces a job
+	Put(ctx context.Context, job types.Job) error
+	// Get returns a job, or ErrNotFound
+	Get(ctx context.Context, id string) (types.Job, error)
+	// PutArtifact stores the artifact of a job
+	PutArtifact(ctx context.Context, id string, artifact *Artifact) error
+	// Artifact returns the artifact of a job, or ErrNotFound
+	Artifact(ctx context.Context, id string) (*Artifact, error)
+	// DeleteFinished removes the jobs finished before a time, with their artifacts
+	DeleteFinished(ctx context.Context, before time.Time) (int, error)
+}
+
+// MemoryStore is a Store in memory; jobs are lost when the process exits
+type MemoryStore struct {
+	mu        sync.RWMutex
+	jobs      map[string]types.Job
+	artifacts map[string]*Artifact
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs:      make(map[string]types.Job),
+		artifacts: make(map[string]*Artifact),
+	}
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(_ context.Context, job types.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, id string) (types.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return types.Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// PutArtifact implements Store
+func (s *MemoryStore) PutArtifact(_ context.Context, id string, artifact *Artifact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[id]; !ok {
+		return ErrNotFound
+	}
+	s.artifacts[id] = artifact
+	return nil
+}
+
+// Artifact implements Store
+func (s *MemoryStore) Artifact(_ context.Context, id string) (*Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	artifact, ok := s.artifacts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return artifact, nil
+}
+
+// DeleteFinished implements Store
+func (s *MemoryStore) DeleteFinished(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(before) {
+			delete(s.jobs, id)
+			delete(s.artifacts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -1,7 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/OkanUysal/go-logger"
@@ -9,9 +18,13 @@ import (
 	"github.com/OkanUysal/go-swagger"
 	"github.com/gin-gonic/gin"
 
+	"github.com/OkanUysal/go-starter-api/catalog"
+	"github.com/OkanUysal/go-starter-api/config"
+	"github.com/OkanUysal/go-starter-api/cors"
 	_ "github.com/OkanUysal/go-starter-api/docs" // Import generated docs
+	"github.com/OkanUysal/go-starter-api/generator"
 	"github.com/OkanUysal/go-starter-api/handlers"
-	"github.com/OkanUysal/go-starter-api/utils"
+	"github.com/OkanUysal/go-starter-api/jobs"
 
 	docs "github.com/OkanUysal/go-starter-api/docs" // Explicit import for SwaggerInfo
 )
@@ -35,16 +48,24 @@ var metricsInstance *metrics.Metrics
 
 // @schemes http https
 func main() {
-	// Initialize logger
-	loggerConfig := &logger.Config{
-		Level:  logger.LevelInfo,
-		Format: logger.FormatJSON,
+	// Server configuration from the YAML file, environment and flags (see -help)
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-	logger.SetDefault(logger.New(loggerConfig))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize logger. The first logger.Default call replaces the default logger,
+	// so it is made before SetDefault or the configured logger would be dropped.
+	logger.Default()
+	logger.SetDefault(logger.New(cfg.Logger()))
+	logger.Info("Configuration loaded", cfg.LogFields()...)
 
-	// Start cleanup routine for temp files (remove files older than 1 hour, check every 30 minutes)
-	utils.StartCleanupRoutine("temp", 1*time.Hour, 30*time.Minute)
-	logger.Info("Cleanup routine started", logger.String("dir", "temp"), logger.String("maxAge", "1h"))
+	// Background work (catalog refresh, job expiry) runs until the server has shut down
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
 
 	// Initialize metrics (Grafana Cloud credentials auto-detected from env vars)
 	metricsConfig := &metrics.Config{
@@ -53,8 +74,14 @@ func main() {
 	metricsInstance = metrics.NewMetrics(metricsConfig)
 
 	// Initialize Gin
-	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	gin.SetMode(cfg.Server.Mode)
+	r := gin.New()
+	r.Use(gin.Logger(), gin.CustomRecovery(handlers.Recover))
+
+	// Unknown routes and methods answer with the API error envelope
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(handlers.NotFound)
+	r.NoMethod(handlers.MethodNotAllowed)
 
 	// Setup metrics endpoints (/metrics and /health)
 	metricsInstance.Setup(r)
@@ -62,21 +89,55 @@ func main() {
 	// Metrics middleware (automatic HTTP metrics collection)
 	r.Use(metricsInstance.GinMiddleware())
 
-	// CORS middleware
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	// CORS policy for the configured origins, methods and headers
+	corsPolicy, err := cors.New(cfg.CORS)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Use(corsPolicy.Handler())
 
 	// Set metrics instance for handlers
 	handlers.SetMetrics(metricsInstance)
 
+	// Library version source: the tags API of each repository host (GitHub, GitLab, Gitea),
+	// or the Go module proxy from GOPROXY with catalog source "proxy"
+	var versionSource catalog.VersionSource
+	switch cfg.Catalog.Source {
+	case "proxy":
+		proxyURL := generator.DefaultModuleProxy()
+		versionSource = catalog.NewProxySource(proxyURL)
+		logger.Info("Library versions from module proxy", logger.String("proxy", proxyURL))
+	default:
+		tokens := catalogTokens()
+		versionSource = catalog.NewVCSSource(tokens)
+		logger.Info("Library versions from repository hosts", logger.Int("tokens", len(tokens)))
+	}
+
+	// Library catalog (versions cached for the catalog TTL and refreshed in the background).
+	// The catalog manifest is a file or directory merged over the built-in libraries.yaml.
+	libraryCatalog, err := catalog.New(catalog.Options{
+		TTL:          cfg.Catalog.TTL,
+		Source:       versionSource,
+		ManifestPath: cfg.Catalog.Manifest,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	libraryCatalog.Start(background)
+	handlers.SetCatalog(libraryCatalog)
+
+	// Asynchronous generation jobs, run by a bounded pool of workers and kept in memory.
+	// Running jobs are canceled when they do not finish within the shutdown timeout.
+	jobQueue := jobs.New(jobs.Options{
+		Workers:   cfg.Jobs.Workers,
+		QueueSize: cfg.Jobs.QueueSize,
+		TTL:       cfg.Jobs.TTL,
+	})
+	jobsCtx, cancelJobs := context.WithCancel(background)
+	defer cancelJobs()
+	jobQueue.Start(jobsCtx)
+	handlers.SetJobs(jobQueue)
+
 	// Swagger documentation with auto host detection
 	swagSpec, err := swagger.LoadSwagDocs(docs.SwaggerInfo.ReadDoc())
 	if err != nil {
@@ -90,13 +151,87 @@ func main() {
 	api := r.Group("/api")
 	{
 		api.GET("/libraries", handlers.GetLibraries)
-		api.POST("/generate", handlers.GenerateProject)
+		api.POST("/generate", handlers.RefuseWhenDraining, handlers.GenerateProject)
+		api.POST("/preview", handlers.RefuseWhenDraining, handlers.PreviewProject)
+		api.POST("/jobs", handlers.RefuseWhenDraining, handlers.CreateJob)
+		api.GET("/jobs/:id", handlers.GetJob)
+		api.GET("/jobs/:id/artifact", handlers.GetJobArtifact)
+		api.GET("/jobs/:id/events", handlers.JobEvents)
 	}
 
-	// Start server
-	port := ":8080"
-	logger.Info("Server starting", logger.String("port", port))
-	if err := r.Run(port); err != nil {
+	// Start server. Requests are canceled, with their generations, when they do not finish
+	// within the shutdown timeout.
+	requests, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server := &http.Server{
+		Addr:         cfg.Addr(),
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return requests },
+	}
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Server starting", logger.String("addr", server.Addr))
+		serveErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
 		log.Fatal(err)
+	case <-signals.Done():
+	}
+	stopSignals() // A second signal kills the process right away
+
+	shutdown(server, jobQueue, cancelJobs, cancelRequests, cfg.Server.ShutdownTimeout)
+	// go-metrics only pushes on its interval, so the metrics since its last push are sent now
+	if err := pushMetrics(context.Background(), metricsInstance.Registry()); err != nil {
+		logger.Warn("Failed to push metrics at shutdown", logger.Err(err))
+	}
+	stopBackground()
+	logger.Info("Server stopped")
+	os.Stdout.Sync()
+}
+
+// shutdown drains the server: new generations are refused, running jobs finish while the API still
+// answers, so clients can follow them and download their archives, queued jobs fail, then in-flight
+// requests finish. Both share timeout; jobs and requests still running after it are canceled.
+func shutdown(server *http.Server, jobQueue *jobs.Queue, cancelJobs, cancelRequests context.CancelFunc, timeout time.Duration) {
+	logger.Info("Shutting down", logger.String("timeout", timeout.String()))
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	handlers.Drain()
+	if err := jobQueue.Shutdown(ctx); err != nil {
+		logger.Warn("Canceling jobs still running at shutdown", logger.Err(err))
+		cancelJobs()
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warn("Canceling requests still running at shutdown", logger.Err(err))
+		cancelRequests()
+		server.Close()
+	}
+}
+
+// catalogTokens returns the tags API tokens by repository host: GITHUB_TOKEN for github.com,
+// GITLAB_TOKEN for gitlab.com and CATALOG_TOKENS ("host=token,host=token") for any host
+func catalogTokens() map[string]string {
+	tokens := make(map[string]string)
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		tokens["github.com"] = token
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		tokens["gitlab.com"] = token
+	}
+	for _, pair := range strings.Split(os.Getenv("CATALOG_TOKENS"), ",") {
+		host, token, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || host == "" || token == "" {
+			continue
+		}
+		tokens[host] = token
 	}
+	return tokens
 }
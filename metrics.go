@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// metricsPushTimeout bounds the last push of metrics at shutdown
+const metricsPushTimeout = 10 * time.Second
+
+// pushMetrics sends the current metrics to Grafana Cloud once and waits for the answer.
+// go-metrics pushes on an interval and has no synchronous flush, so the last push at shutdown is made
+// here, with the same remote write request and credentials (GRAFANA_CLOUD_URL, _USER and _KEY).
+// It does nothing when Grafana Cloud is not configured.
+func pushMetrics(ctx context.Context, registry *prometheus.Registry) error {
+	pushURL, user, key := os.Getenv("GRAFANA_CLOUD_URL"), os.Getenv("GRAFANA_CLOUD_USER"), os.Getenv("GRAFANA_CLOUD_KEY")
+	if pushURL == "" || key == "" {
+		return nil
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		return fmt.Errorf("gather metrics: %w", err)
+	}
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: timeSeries(families, time.Now())})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metricsPushTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", pushURL, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.SetBasicAuth(user, key)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("metrics push returned %s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// timeSeries converts gathered metrics to remote write samples the way go-metrics does:
+// counters and gauges by value, summaries and histograms by the sum of their samples
+func timeSeries(families []*dto.MetricFamily, now time.Time) []prompb.TimeSeries {
+	var series []prompb.TimeSeries
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := []prompb.Label{{Name: "__name__", Value: family.GetName()}}
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, prompb.Label{Name: label.GetName(), Value: label.GetValue()})
+			}
+
+			var value float64
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				value = metric.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				value = metric.GetGauge().GetValue()
+			case dto.MetricType_SUMMARY:
+				value = metric.GetSummary().GetSampleSum()
+			case dto.MetricType_HISTOGRAM:
+				value = metric.GetHistogram().GetSampleSum()
+			}
+
+			series = append(series, prompb.TimeSeries{
+				Labels:  labels,
+				Samples: []prompb.Sample{{Value: value, Timestamp: now.UnixMilli()}},
+			})
+		}
+	}
+	return series
+}
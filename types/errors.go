@@ -0,0 +1,55 @@
+package types
+
+// Error codes of ErrorResponse and ErrorDetail. They are stable, so clients can localize messages by code.
+const (
+	CodeInvalidBody        = "invalid_body"        // The request body is not valid JSON for the endpoint
+	CodeInvalidQuery       = "invalid_query"       // A query parameter has an invalid value
+	CodeValidationFailed   = "validation_failed"   // Request fields are invalid, see details
+	CodeRuleViolation      = "rule_violation"      // The library selection breaks catalog rules, see details
+	CodeGenerationFailed   = "generation_failed"   // The project could not be generated
+	CodeArchiveFailed      = "archive_failed"      // The project could not be packaged
+	CodeNotFound           = "not_found"           // No such endpoint or job
+	CodeMethodNotAllowed   = "method_not_allowed"  // The endpoint does not support the method
+	CodeOriginNotAllowed   = "origin_not_allowed"  // The CORS policy does not allow the origin of a preflight request
+	CodeInternal           = "internal_error"      // Unexpected server error
+	CodeRequired           = "required"            // Detail: the field is required
+	CodeInvalidFormat      = "invalid_format"      // Detail: the value does not match the allowed format
+	CodeUnknownValue       = "unknown_value"       // Detail: the value is not one of the allowed values
+	CodeUnknownLibrary     = "unknown_library"     // Detail: the library is not in the catalog
+	CodeDuplicateLibrary   = "duplicate_library"   // Detail: the library is listed more than once
+	CodeInvalidVersion     = "invalid_version"     // Detail: the version is not a semantic version
+	CodeRequiresLibrary    = "requires_library"    // Detail: a selected library requires another one
+	CodeConflictingLibrary = "conflicting_library" // Detail: two selected libraries cannot be used together
+	CodeRequiresDatabase   = "requires_database"   // Detail: a selected library does not work with the database
+	CodeQueueFull          = "queue_full"          // Too many generation jobs are waiting, retry later
+	CodeShuttingDown       = "shutting_down"       // The server is shutting down and takes no more jobs
+	CodeJobPending         = "job_pending"         // The job has no artifact yet, poll its status
+	CodeJobFailed          = "job_failed"          // The job failed and has no artifact
+)
+
+// ErrorResponse is the body of every failed API request
+type ErrorResponse struct {
+	Success bool     `json:"success"` // Always false
+	Error   APIError `json:"error"`
+}
+
+// APIError describes why a request failed
+type APIError struct {
+	Code    string        `json:"code" example:"validation_failed"`
+	Message string        `json:"message" example:"Project name is required"` // English message, for logs and fallback display
+	Field   string        `json:"field,omitempty" example:"name"`             // JSON path of the field at fault, if there is one
+	Details []ErrorDetail `json:"details,omitempty"`                          // Every problem found, when there are several
+}
+
+// Error implements error, so failures can be passed on with their code
+func (e APIError) Error() string {
+	return e.Message
+}
+
+// ErrorDetail is one problem of a failed request
+type ErrorDetail struct {
+	Code    string            `json:"code" example:"unknown_library"`
+	Message string            `json:"message" example:"Unknown library \"go-orm\""`
+	Field   string            `json:"field,omitempty" example:"libraries[2]"` // JSON path of the field at fault (e.g. "libraries[2]", "versions.go-auth")
+	Params  map[string]string `json:"params,omitempty"`                       // Values used in the message, for localized messages
+}
@@ -1,13 +1,27 @@
 package types
 
+import "time"
+
 // GenerateRequest represents the project generation request
 type GenerateRequest struct {
-	Name       string         `json:"name"`
-	ModulePath string         `json:"modulePath"`
-	Structure  string         `json:"structure"` // "simple" or "standard"
-	Database   DatabaseConfig `json:"database"`
-	Libraries  []string       `json:"libraries"`
-	Deployment string         `json:"deployment"` // "railway", "local", "docker"
+	Name                string            `json:"name"`
+	ModulePath          string            `json:"modulePath"`
+	Structure           string            `json:"structure"` // "simple" or "standard"
+	Database            DatabaseConfig    `json:"database"`
+	Libraries           []string          `json:"libraries"`
+	Deployment          string            `json:"deployment"`          // "railway", "local", "docker"
+	Verify              bool              `json:"verify"`              // Type-check the generated code before packaging
+	Versions            map[string]string `json:"versions,omitempty"`  // Per-library version overrides (e.g. {"go-auth": "v1.2.0"})
+	Prerelease          bool              `json:"prerelease"`          // Use pre-release library versions newer than the latest stable
+	ResolveDependencies bool              `json:"resolveDependencies"` // Tidy go.mod and write go.sum through the module proxy
+	AddImplied          bool              `json:"addImplied"`          // Add the libraries implied by the selected ones
+	Format              string            `json:"format,omitempty"`    // Archive format: "zip", "tar.gz" or "tar.zst"; negotiated from Accept when empty
+}
+
+// PreviewRequest is a generate request whose project is returned as JSON instead of a ZIP file
+type PreviewRequest struct {
+	GenerateRequest
+	IncludeContents bool `json:"includeContents"` // Return the content of every file, not only its path and size
 }
 
 // DatabaseConfig holds database configuration
@@ -17,141 +31,87 @@ type DatabaseConfig struct {
 
 // Library represents an available library
 type Library struct {
-	Name        string `json:"name"`
-	DisplayName string `json:"displayName"`
-	Description string `json:"description"`
-	Version     string `json:"version"`
-	RepoURL     string `json:"repoURL"`
-	Category    string `json:"category"`
-	RequiresDB  bool   `json:"requiresDB"`
+	Name             string   `json:"name"` // Catalog name, prefixed with "source/" outside the built-in catalog
+	DisplayName      string   `json:"displayName"`
+	Description      string   `json:"description"`
+	Version          string   `json:"version"`                    // Version used in generated go.mod files
+	LatestStable     string   `json:"latestStable,omitempty"`     // Highest stable tag of the repository
+	LatestPrerelease string   `json:"latestPrerelease,omitempty"` // Highest pre-release tag newer than LatestStable
+	RepoURL          string   `json:"repoURL"`
+	ModulePath       string   `json:"modulePath"`         // Go module path used in go.mod and imports
+	Source           string   `json:"source"`             // Catalog the library comes from ("builtin" or an organization)
+	Provider         string   `json:"provider,omitempty"` // Tags API of the repository host: "github", "gitlab" or "gitea"
+	APIURL           string   `json:"apiURL,omitempty"`   // Base URL of a self-hosted tags API
+	Category         string   `json:"category"`
+	RequiresDB       bool     `json:"requiresDB"`
+	Databases        []string `json:"databases,omitempty"` // Database types the library works with, any when empty
+	Requires         []string `json:"requires,omitempty"`  // Libraries that must be selected with this one
+	Conflicts        []string `json:"conflicts,omitempty"` // Libraries that cannot be selected with this one
+	Implies          []string `json:"implies,omitempty"`   // Libraries added with this one when addImplied is set
+}
+
+// Violation is a catalog rule broken by the selected libraries
+type Violation struct {
+	Rule    string `json:"rule"`             // "requires", "conflicts" or "database"
+	Library string `json:"library"`          // Library declaring the rule
+	Target  string `json:"target,omitempty"` // Library or database the rule is about
+	Message string `json:"message"`
+}
+
+// LibrariesResponse is the response of GET /api/libraries
+type LibrariesResponse struct {
+	Success bool      `json:"success"`
+	Data    []Library `json:"data"`
+	Count   int       `json:"count"`
+	Sources []string  `json:"sources"` // Sources of all libraries, also when filtered by source
+}
+
+// PreviewResponse is the response of POST /api/preview
+type PreviewResponse struct {
+	Success bool          `json:"success"`
+	Files   []PreviewFile `json:"files"` // Files and directories of the project, sorted by path
+	Count   int           `json:"count"` // Number of files
+	Size    int64         `json:"size"`  // Total size of the files in bytes
 }
 
-// GenerateResponse represents the generation response
-type GenerateResponse struct {
-	Success     bool   `json:"success"`
-	DownloadURL string `json:"downloadUrl"`
-	FileName    string `json:"fileName"`
-	Message     string `json:"message,omitempty"`
-	Error       string `json:"error,omitempty"`
+// PreviewFile is a file or directory of a previewed project
+type PreviewFile struct {
+	Path    string `json:"path"` // Slash-separated path relative to the project root
+	Dir     bool   `json:"dir,omitempty"`
+	Size    int64  `json:"size"`
+	Content string `json:"content,omitempty"` // Set with includeContents
 }
 
-// GetAvailableLibraries returns all available libraries with latest versions from GitHub
-func GetAvailableLibraries() []Library {
-	// Fetch latest versions from GitHub
-	versions := FetchLatestVersions()
+// Job is an asynchronous project generation
+type Job struct {
+	ID         string       `json:"id"`
+	Status     string       `json:"status" example:"running"` // "queued", "running", "succeeded" or "failed"
+	Progress   JobProgress  `json:"progress"`
+	Error      *APIError    `json:"error,omitempty"`    // Why the job failed
+	Artifact   *JobArtifact `json:"artifact,omitempty"` // The generated archive, once the job succeeded
+	CreatedAt  time.Time    `json:"createdAt"`
+	StartedAt  *time.Time   `json:"startedAt,omitempty"`
+	FinishedAt *time.Time   `json:"finishedAt,omitempty"`
+}
 
-	// Default versions (fallback if GitHub API fails)
-	defaultVersions := map[string]string{
-		"go-auth":       "v1.0.0",
-		"go-migration":  "v1.0.0",
-		"go-logger":     "v1.0.1",
-		"go-cache":      "v1.0.0",
-		"go-swagger":    "v1.1.1",
-		"go-response":   "v1.0.0",
-		"go-validator":  "v1.0.0",
-		"go-pagination": "v1.0.0",
-		"go-websocket":  "v1.0.0",
-		"go-metrics":    "v1.0.5",
-	}
+// JobProgress tells how far a job got
+type JobProgress struct {
+	Phase string `json:"phase" example:"handlers"` // Current phase, or the last one once the job finished
+	Step  int    `json:"step"`                     // Completed steps
+	Steps int    `json:"steps"`                    // Total steps
+}
 
-	// Helper to get version (GitHub or fallback)
-	getVersion := func(name string) string {
-		if v, ok := versions[name]; ok && v != "" {
-			return v
-		}
-		return defaultVersions[name]
-	}
+// JobArtifact describes the archive of a finished job
+type JobArtifact struct {
+	FileName    string `json:"fileName" example:"my-api.zip"`
+	ContentType string `json:"contentType" example:"application/zip"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	URL         string `json:"url" example:"/api/jobs/4f1c.../artifact"` // Download URL
+}
 
-	return []Library{
-		{
-			Name:        "go-auth",
-			DisplayName: "Authentication",
-			Description: "JWT authentication & authorization with middleware",
-			Version:     getVersion("go-auth"),
-			RepoURL:     "https://github.com/OkanUysal/go-auth",
-			Category:    "Security",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-migration",
-			DisplayName: "Database Migration",
-			Description: "Database migrations for PostgreSQL, MySQL, MongoDB",
-			Version:     getVersion("go-migration"),
-			RepoURL:     "https://github.com/OkanUysal/go-migration",
-			Category:    "Database",
-			RequiresDB:  true,
-		},
-		{
-			Name:        "go-logger",
-			DisplayName: "Structured Logger",
-			Description: "High-performance structured logging with Zap",
-			Version:     getVersion("go-logger"),
-			RepoURL:     "https://github.com/OkanUysal/go-logger",
-			Category:    "Observability",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-cache",
-			DisplayName: "Caching",
-			Description: "Multi-backend caching (Redis, In-Memory)",
-			Version:     getVersion("go-cache"),
-			RepoURL:     "https://github.com/OkanUysal/go-cache",
-			Category:    "Performance",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-swagger",
-			DisplayName: "API Documentation",
-			Description: "Automatic Swagger/OpenAPI documentation",
-			Version:     getVersion("go-swagger"),
-			RepoURL:     "https://github.com/OkanUysal/go-swagger",
-			Category:    "Documentation",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-response",
-			DisplayName: "API Response",
-			Description: "Standardized API response format",
-			Version:     getVersion("go-response"),
-			RepoURL:     "https://github.com/OkanUysal/go-response",
-			Category:    "API",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-validator",
-			DisplayName: "Request Validator",
-			Description: "Request validation with custom rules",
-			Version:     getVersion("go-validator"),
-			RepoURL:     "https://github.com/OkanUysal/go-validator",
-			Category:    "API",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-pagination",
-			DisplayName: "Pagination",
-			Description: "Offset & cursor-based pagination",
-			Version:     getVersion("go-pagination"),
-			RepoURL:     "https://github.com/OkanUysal/go-pagination",
-			Category:    "API",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-websocket",
-			DisplayName: "WebSocket",
-			Description: "Real-time WebSocket with room management",
-			Version:     getVersion("go-websocket"),
-			RepoURL:     "https://github.com/OkanUysal/go-websocket",
-			Category:    "Real-time",
-			RequiresDB:  false,
-		},
-		{
-			Name:        "go-metrics",
-			DisplayName: "Metrics & Monitoring",
-			Description: "Prometheus metrics with Grafana integration",
-			Version:     getVersion("go-metrics"),
-			RepoURL:     "https://github.com/OkanUysal/go-metrics",
-			Category:    "Observability",
-			RequiresDB:  false,
-		},
-	}
+// JobResponse is the response of the job endpoints
+type JobResponse struct {
+	Success bool `json:"success"`
+	Data    Job  `json:"data"`
 }